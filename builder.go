@@ -0,0 +1,246 @@
+package telegraph
+
+// The builder functions below construct content with the tags and attributes supported by Telegraph only, e.g.
+//
+//	content := telegraph.Nodes(
+//		telegraph.H3(telegraph.Text("Title")),
+//		telegraph.P(telegraph.Text("Hello "), telegraph.B("world"), telegraph.Text(", see "),
+//			telegraph.A("https://telegra.ph/", "this link", telegraph.I(" and more"))),
+//		telegraph.Figure(telegraph.Img("/file/a086583f5b7b25cd428fb.jpg"), telegraph.Figcaption(telegraph.Text("Caption"))),
+//	)
+//	page, err := client.CreatePageNodes(accessToken, "Sample", content, nil)
+//
+// Their parameters only accept the results of the builders that are valid in their place, so that blocks cannot be
+// nested in paragraphs, lists only hold list items and so on. The text elements B, Strong, I, Em, U, S and A start
+// with their text, which may be followed by more Inline content, e.g. B("bold and ", I("italic")). Links and media
+// whose URL has an unsafe scheme, like javascript:, are built without it, the same way ContentFormat drops it.
+
+// Block is a top-level element of a page, built by P, H3, H4, Blockquote, Aside, Pre, Hr, Ul, Ol, Figure, Img,
+// Iframe or Video.
+type Block interface {
+	blockNode() Node
+}
+
+// Inline is the content of paragraphs and other text elements: text built by Text, or an element built by B, Strong,
+// I, Em, U, S, Code, A or Br.
+type Inline interface {
+	inlineNode() Node
+}
+
+// ListItemContent is the content of a list item: Inline content, or a nested list built by Ul or Ol.
+type ListItemContent interface {
+	listItemNode() Node
+}
+
+// built holds an element made by a builder function.
+type built struct{ e *NodeElement }
+
+// node returns the built element, or nil for a zero value, which was not made by a builder function.
+func (b built) node() Node {
+	if b.e == nil {
+		return nil
+	}
+	return b.e
+}
+
+// InlineElement is an inline element built by B, Strong, I, Em, U, S, Code, A or Br.
+type InlineElement struct{ built }
+
+// BlockElement is a block element built by P, H3, H4, Blockquote, Aside, Pre, Hr or Figure.
+type BlockElement struct{ built }
+
+// ListElement is a list built by Ul or Ol, which may also be nested in a list item.
+type ListElement struct{ built }
+
+// ListItem is a list item built by Li.
+type ListItem struct{ built }
+
+// FigureCaption is the caption of a Figure built by Figcaption.
+type FigureCaption struct{ built }
+
+// MediaElement is an image, embed or video built by Img, Iframe or Video, which may also be wrapped in a Figure.
+type MediaElement struct{ built }
+
+func (t TextNode) inlineNode() Node   { return t }
+func (t TextNode) listItemNode() Node { return t }
+
+func (e InlineElement) inlineNode() Node   { return e.node() }
+func (e InlineElement) listItemNode() Node { return e.node() }
+func (e BlockElement) blockNode() Node     { return e.node() }
+func (e ListElement) blockNode() Node      { return e.node() }
+func (e ListElement) listItemNode() Node   { return e.node() }
+func (e MediaElement) blockNode() Node     { return e.node() }
+
+// Nodes returns the given blocks as a []Node, ready to be passed to CreatePageNodes or EditPageNodes.
+func Nodes(blocks ...Block) []Node {
+	var nodes []Node
+	for _, b := range blocks {
+		if b != nil {
+			nodes = appendNode(nodes, b.blockNode())
+		}
+	}
+	return nodes
+}
+
+func appendNode(nodes []Node, n Node) []Node {
+	if n == nil {
+		return nodes
+	}
+	return append(nodes, n)
+}
+
+func inlineNodes(children []Inline) []Node {
+	var nodes []Node
+	for _, c := range children {
+		if c != nil {
+			nodes = appendNode(nodes, c.inlineNode())
+		}
+	}
+	return nodes
+}
+
+func element(tag string, attrs map[string]string, children []Node) *NodeElement {
+	return &NodeElement{Tag: tag, Attrs: attrs, Children: children}
+}
+
+func inline(tag string, children []Inline) InlineElement {
+	return InlineElement{built{element(tag, nil, inlineNodes(children))}}
+}
+
+// textInline returns an inline element holding text, if not empty, followed by children.
+func textInline(tag string, attrs map[string]string, text string, children []Inline) InlineElement {
+	var nodes []Node
+	if text != "" {
+		nodes = append(nodes, TextNode(text))
+	}
+	nodes = append(nodes, inlineNodes(children)...)
+	return InlineElement{built{element(tag, attrs, nodes)}}
+}
+
+func block(tag string, children []Inline) BlockElement {
+	return BlockElement{built{element(tag, nil, inlineNodes(children))}}
+}
+
+// urlAttr returns the attributes holding the URL u under key, or nil if its scheme is unsafe.
+func urlAttr(key, u string) map[string]string {
+	if !safeURL(u, nil) {
+		return nil
+	}
+	return map[string]string{key: u}
+}
+
+// Text returns a text node.
+func Text(s string) TextNode { return TextNode(s) }
+
+// P returns a paragraph.
+func P(children ...Inline) BlockElement { return block("p", children) }
+
+// H3 returns a heading, the largest one supported by Telegraph.
+func H3(children ...Inline) BlockElement { return block("h3", children) }
+
+// H4 returns a subheading.
+func H4(children ...Inline) BlockElement { return block("h4", children) }
+
+// B returns bold text.
+func B(text string, children ...Inline) InlineElement { return textInline("b", nil, text, children) }
+
+// Strong returns strongly emphasized text.
+func Strong(text string, children ...Inline) InlineElement {
+	return textInline("strong", nil, text, children)
+}
+
+// I returns italic text.
+func I(text string, children ...Inline) InlineElement { return textInline("i", nil, text, children) }
+
+// Em returns emphasized text.
+func Em(text string, children ...Inline) InlineElement { return textInline("em", nil, text, children) }
+
+// U returns underlined text.
+func U(text string, children ...Inline) InlineElement { return textInline("u", nil, text, children) }
+
+// S returns struck through text.
+func S(text string, children ...Inline) InlineElement { return textInline("s", nil, text, children) }
+
+// Code returns inline code, or the code of a Pre block.
+func Code(code string) InlineElement { return inline("code", []Inline{TextNode(code)}) }
+
+// A returns a link to href. The link has no href if its scheme is unsafe.
+func A(href, text string, children ...Inline) InlineElement {
+	return textInline("a", urlAttr("href", href), text, children)
+}
+
+// Br returns a line break.
+func Br() InlineElement { return inline("br", nil) }
+
+// Hr returns a horizontal rule.
+func Hr() BlockElement { return block("hr", nil) }
+
+// Blockquote returns a quote.
+func Blockquote(children ...Inline) BlockElement { return block("blockquote", children) }
+
+// Aside returns a pull quote.
+func Aside(children ...Inline) BlockElement { return block("aside", children) }
+
+// Pre returns a block of preformatted text, usually holding Code.
+func Pre(children ...Inline) BlockElement { return block("pre", children) }
+
+// Ul returns an unordered list.
+func Ul(items ...ListItem) ListElement { return list("ul", items) }
+
+// Ol returns an ordered list.
+func Ol(items ...ListItem) ListElement { return list("ol", items) }
+
+func list(tag string, items []ListItem) ListElement {
+	var nodes []Node
+	for _, item := range items {
+		nodes = appendNode(nodes, item.node())
+	}
+	return ListElement{built{element(tag, nil, nodes)}}
+}
+
+// Li returns a list item.
+func Li(children ...ListItemContent) ListItem {
+	var nodes []Node
+	for _, c := range children {
+		if c != nil {
+			nodes = appendNode(nodes, c.listItemNode())
+		}
+	}
+	return ListItem{built{element("li", nil, nodes)}}
+}
+
+// Figure returns a figure holding an Img, Iframe or Video, with a caption if one is given. The content of several
+// captions is joined into one, as a figure only has a single caption.
+func Figure(media MediaElement, caption ...FigureCaption) BlockElement {
+	nodes := appendNode(nil, media.node())
+	var captionNodes []Node
+	for _, c := range caption {
+		if c.e != nil {
+			captionNodes = append(captionNodes, c.e.Children...)
+		}
+	}
+	if len(captionNodes) > 0 {
+		nodes = append(nodes, element("figcaption", nil, captionNodes))
+	}
+	return BlockElement{built{element("figure", nil, nodes)}}
+}
+
+// Figcaption returns the caption of a Figure.
+func Figcaption(children ...Inline) FigureCaption {
+	return FigureCaption{built{element("figcaption", nil, inlineNodes(children))}}
+}
+
+// Img returns an image, src may be a path returned by UploadFile. The image has no src if its scheme is unsafe.
+func Img(src string) MediaElement {
+	return MediaElement{built{element("img", urlAttr("src", src), nil)}}
+}
+
+// Iframe returns an embed, e.g. /embed/youtube?url=<video url>. The embed has no src if its scheme is unsafe.
+func Iframe(src string) MediaElement {
+	return MediaElement{built{element("iframe", urlAttr("src", src), nil)}}
+}
+
+// Video returns a video, src may be a path returned by UploadFile. The video has no src if its scheme is unsafe.
+func Video(src string) MediaElement {
+	return MediaElement{built{element("video", urlAttr("src", src), nil)}}
+}
@@ -7,60 +7,269 @@ import (
 	"strings"
 
 	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
 )
 
-func ContentFormat(data interface{}) (n []Node, err error) {
-	var dst *html.Node
+// TagRule tells ContentFormat how to convert an HTML element.
+type TagRule struct {
+	// Drop removes the element together with all of its children.
+	Drop bool
+	// Tag is the name of the supported tag the element is converted to. If empty (and Drop is false), the
+	// element is unwrapped: it is removed but its children are kept in its place.
+	Tag string
+	// Separator is text inserted before the element when it follows other content of its parent, e.g. to keep
+	// the cells of a table row apart once they are unwrapped.
+	Separator string
+}
+
+// DefaultTagRules is the set of rules used by ContentFormat for the HTML tags that Telegraph does not support.
+// Unsupported tags which are not listed here are unwrapped.
+var DefaultTagRules = map[string]TagRule{
+	"h1":       {Tag: "h3"},
+	"h2":       {Tag: "h3"},
+	"h5":       {Tag: "h4"},
+	"h6":       {Tag: "h4"},
+	"div":      {Tag: "p"},
+	"del":      {Tag: "s"},
+	"strike":   {Tag: "s"},
+	"ins":      {Tag: "u"},
+	"tt":       {Tag: "code"},
+	"kbd":      {Tag: "code"},
+	"samp":     {Tag: "code"},
+	"cite":     {Tag: "i"},
+	"dfn":      {Tag: "i"},
+	"var":      {Tag: "i"},
+	"mark":     {Tag: "b"},
+	"q":        {Tag: "i"},
+	"tr":       {Tag: "p"},
+	"td":       {Separator: " | "},
+	"th":       {Separator: " | "},
+	"dt":       {Tag: "b"},
+	"dd":       {Tag: "p"},
+	"head":     {Drop: true},
+	"title":    {Drop: true},
+	"meta":     {Drop: true},
+	"link":     {Drop: true},
+	"script":   {Drop: true},
+	"noscript": {Drop: true},
+	"style":    {Drop: true},
+	"template": {Drop: true},
+	"object":   {Drop: true},
+	"embed":    {Drop: true},
+	"svg":      {Drop: true},
+	"canvas":   {Drop: true},
+	"form":     {Drop: true},
+	"input":    {Drop: true},
+	"button":   {Drop: true},
+	"select":   {Drop: true},
+	"textarea": {Drop: true},
+}
+
+// supportedTags is the set of tags accepted by Telegraph.
+var supportedTags = map[string]bool{
+	"a": true, "aside": true, "b": true, "blockquote": true, "br": true, "code": true, "em": true,
+	"figcaption": true, "figure": true, "h3": true, "h4": true, "hr": true, "i": true, "iframe": true,
+	"img": true, "li": true, "ol": true, "p": true, "pre": true, "s": true, "strong": true, "u": true,
+	"ul": true, "video": true,
+}
+
+// supportedAttrs is the set of attributes accepted by Telegraph, along with the tags they apply to.
+var supportedAttrs = map[string]map[string]bool{
+	"href": {"a": true},
+	"src":  {"img": true, "iframe": true, "video": true},
+}
+
+// unsafeSchemes is the set of URL schemes dropped from attributes by default.
+var unsafeSchemes = []string{"javascript", "vbscript", "data"}
+
+// ContentFormatOpts is the optional parameters for ContentFormatWithOpts.
+type ContentFormatOpts struct {
+	// Tags overrides the rule used for the given (lowercase) tag names. It takes precedence over both the supported
+	// tags and DefaultTagRules, e.g. {"iframe": {Drop: true}} removes all embeds.
+	Tags map[string]TagRule
+	// UnknownTag is the rule used for unsupported tags that are neither in Tags nor in DefaultTagRules.
+	// The zero value unwraps them.
+	UnknownTag TagRule
+	// AllowSchemes lists the URL schemes (e.g. "data") that are kept in href and src attributes although they are
+	// dropped by default. The javascript, vbscript and data schemes are dropped unless listed here.
+	AllowSchemes []string
+	// KeepWhitespace disables whitespace collapsing, so that text nodes are sent verbatim. By default whitespace is
+	// collapsed the way browsers render it, except inside <pre> elements.
+	KeepWhitespace bool
+}
+
+// rule returns the rule for the given lowercase tag name.
+func (o *ContentFormatOpts) rule(tag string) TagRule {
+	if r, ok := o.Tags[tag]; ok {
+		return r
+	}
+	if supportedTags[tag] {
+		return TagRule{Tag: tag}
+	}
+	if r, ok := DefaultTagRules[tag]; ok {
+		return r
+	}
+	return o.UnknownTag
+}
+
+// ContentFormat converts HTML into the Telegraph content format, an array of Node.
+// The HTML is parsed as a fragment in a <body> context and its top-level nodes are returned. Unsupported tags are
+// converted according to DefaultTagRules.
+// - data (type string, []byte or io.Reader): HTML to convert.
+// https://telegra.ph/api#Content-format
+func ContentFormat(data interface{}) ([]Node, error) {
+	return ContentFormatWithOpts(data, nil)
+}
+
+// ContentFormatWithOpts is like ContentFormat but lets the conversion be customized.
+// - data (type string, []byte or io.Reader): HTML to convert.
+// - opts (type ContentFormatOpts): All optional parameters.
+func ContentFormatWithOpts(data interface{}, opts *ContentFormatOpts) (n []Node, err error) {
+	var r io.Reader
 
 	switch src := data.(type) {
 	case string:
-		dst, err = html.Parse(strings.NewReader(src))
+		r = strings.NewReader(src)
 	case []byte:
-		dst, err = html.Parse(bytes.NewReader(src))
+		r = bytes.NewReader(src)
 	case io.Reader:
-		dst, err = html.Parse(src)
+		r = src
 	default:
 		return nil, errors.New("INVALID DATA TYPE")
 	}
+	if opts == nil {
+		opts = &ContentFormatOpts{}
+	}
 
+	dst, err := html.ParseFragment(r, &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	n = append(n, domToNode(dst.FirstChild))
+	for _, domNode := range dst {
+		n = appendDomNode(n, domNode, opts)
+	}
+	if !opts.KeepWhitespace {
+		n = collapseWhitespace(n)
+	}
 
 	return n, nil
 }
 
-func domToNode(domNode *html.Node) interface{} {
+// appendDomNode converts domNode and appends the result to nodes. Unwrapped elements append their children.
+func appendDomNode(nodes []Node, domNode *html.Node, opts *ContentFormatOpts) []Node {
 	if domNode.Type == html.TextNode {
-		return domNode.Data
+		return appendText(nodes, TextNode(domNode.Data))
 	}
 
+	// Comments, doctypes and the like have no counterpart in the Telegraph content format.
 	if domNode.Type != html.ElementNode {
-		return nil
+		return nodes
 	}
 
-	nodeElement := new(NodeElement)
+	rule := opts.rule(strings.ToLower(domNode.Data))
+	if rule.Drop {
+		return nodes
+	}
 
-	switch strings.ToLower(domNode.Data) {
-	case "a", "aside", "b", "blockquote", "br", "code", "em", "figcaption", "figure", "h3", "h4", "hr", "i",
-		"iframe", "img", "li", "ol", "p", "pre", "s", "strong", "u", "ul", "video":
-		nodeElement.Tag = domNode.Data
+	if rule.Separator != "" && hasContent(nodes) {
+		nodes = appendText(nodes, TextNode(rule.Separator))
+	}
 
-		for i := range domNode.Attr {
-			switch strings.ToLower(domNode.Attr[i].Key) {
-			case "href", "src":
-				nodeElement.Attrs = map[string]string{domNode.Attr[i].Key: domNode.Attr[i].Val}
-			default:
-				continue
-			}
+	var children []Node
+	for child := domNode.FirstChild; child != nil; child = child.NextSibling {
+		children = appendDomNode(children, child, opts)
+	}
+	// Elements mapped onto a tag that cannot hold blocks, like a div mapped onto p, are unwrapped when they do.
+	if rule.Tag == "" || (inlineOnlyTags[rule.Tag] && hasBlock(children)) {
+		for _, child := range children {
+			nodes = appendText(nodes, child)
 		}
+		return nodes
 	}
 
-	for child := domNode.FirstChild; child != nil; child = child.NextSibling {
-		nodeElement.Children = append(nodeElement.Children, domToNode(child))
+	nodeElement := &NodeElement{
+		Tag:      rule.Tag,
+		Children: children,
 	}
+	for _, attr := range domNode.Attr {
+		key := strings.ToLower(attr.Key)
+		if !supportedAttrs[key][nodeElement.Tag] || !opts.allowURL(attr.Val) {
+			continue
+		}
+		if nodeElement.Attrs == nil {
+			nodeElement.Attrs = map[string]string{}
+		}
+		nodeElement.Attrs[key] = attr.Val
+	}
+
+	return append(nodes, nodeElement)
+}
+
+// appendText appends n to nodes, merging it with the last node if both are text.
+func appendText(nodes []Node, n Node) []Node {
+	if t, ok := n.(TextNode); ok && len(nodes) > 0 {
+		if last, ok := nodes[len(nodes)-1].(TextNode); ok {
+			nodes[len(nodes)-1] = last + t
+			return nodes
+		}
+	}
+	return append(nodes, n)
+}
+
+// hasContent reports whether nodes ends with anything but whitespace.
+func hasContent(nodes []Node) bool {
+	if len(nodes) == 0 {
+		return false
+	}
+	t, ok := nodes[len(nodes)-1].(TextNode)
+	return !ok || strings.TrimSpace(string(t)) != ""
+}
+
+// inlineOnlyTags is the set of supported block tags that can only contain inline content.
+var inlineOnlyTags = map[string]bool{"p": true, "h3": true, "h4": true}
 
-	return nodeElement
+func hasBlock(nodes []Node) bool {
+	for _, n := range nodes {
+		if e, ok := n.(*NodeElement); ok && blockTags[e.Tag] {
+			return true
+		}
+	}
+	return false
+}
+
+// allowURL reports whether the scheme of the URL u is allowed.
+func (o *ContentFormatOpts) allowURL(u string) bool {
+	return safeURL(u, o.AllowSchemes)
+}
+
+// safeURL reports whether the scheme of the URL u is safe: not in unsafeSchemes, unless it is listed in allow.
+func safeURL(u string, allow []string) bool {
+	// Browsers ignore whitespace and control characters inside the scheme, e.g. "java\tscript:".
+	u = strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, u)
+	i := strings.IndexByte(u, ':')
+	if i < 0 {
+		return true
+	}
+	scheme := strings.ToLower(u[:i])
+	for _, s := range allow {
+		if strings.EqualFold(s, scheme) {
+			return true
+		}
+	}
+	for _, s := range unsafeSchemes {
+		if s == scheme {
+			return false
+		}
+	}
+	return true
 }
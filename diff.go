@@ -0,0 +1,423 @@
+package telegraph
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind is the kind of a Change between two Node trees.
+type ChangeKind int
+
+const (
+	// ChangeInserted is a node present in the new tree only.
+	ChangeInserted ChangeKind = iota
+	// ChangeDeleted is a node present in the old tree only.
+	ChangeDeleted
+	// ChangeModified is an element replaced by an element with another tag.
+	ChangeModified
+	// ChangeText is a text node whose text changed.
+	ChangeText
+	// ChangeAttrs is an element whose attributes changed.
+	ChangeAttrs
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeInserted:
+		return "inserted"
+	case ChangeDeleted:
+		return "deleted"
+	case ChangeModified:
+		return "modified"
+	case ChangeText:
+		return "text changed"
+	case ChangeAttrs:
+		return "attrs changed"
+	}
+	return "ChangeKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Change is a difference between two Node trees, as reported by DiffNodes.
+type Change struct {
+	// Kind of the change.
+	Kind ChangeKind
+	// OldPath is the position of Old in the old tree, as the child indexes leading to it. Nil for insertions.
+	OldPath []int
+	// NewPath is the position of New in the new tree, as the child indexes leading to it. Nil for deletions.
+	NewPath []int
+	// Old is the node of the old tree. Nil for insertions.
+	Old Node
+	// New is the node of the new tree. Nil for deletions.
+	New Node
+	// Attrs lists the names of the attributes that were added, removed or changed, for ChangeAttrs.
+	Attrs []string
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeInserted:
+		return fmt.Sprintf("%s %s at %s", c.Kind, describeNode(c.New), formatPath(c.NewPath))
+	case ChangeDeleted:
+		return fmt.Sprintf("%s %s at %s", c.Kind, describeNode(c.Old), formatPath(c.OldPath))
+	case ChangeAttrs:
+		old, new := c.Old.(*NodeElement), c.New.(*NodeElement)
+		parts := make([]string, len(c.Attrs))
+		for i, k := range c.Attrs {
+			parts[i] = fmt.Sprintf("%s %q -> %q", k, old.Attrs[k], new.Attrs[k])
+		}
+		return fmt.Sprintf("%s of <%s> at %s: %s", c.Kind, new.Tag, formatPath(c.NewPath), strings.Join(parts, ", "))
+	default:
+		return fmt.Sprintf("%s at %s: %s -> %s", c.Kind, formatPath(c.NewPath), describeNode(c.Old), describeNode(c.New))
+	}
+}
+
+// NodesEqual reports whether a and b are the same trees. TextNode and string text nodes are considered equal.
+func NodesEqual(a, b []Node) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !nodeEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func nodeEqual(a, b Node) bool {
+	at, aText := nodeText(a)
+	bt, bText := nodeText(b)
+	if aText || bText {
+		return aText && bText && at == bt
+	}
+	ae, aok := a.(*NodeElement)
+	be, bok := b.(*NodeElement)
+	if !aok || !bok {
+		return a == nil && b == nil
+	}
+	return ae.Tag == be.Tag && len(diffAttrs(ae.Attrs, be.Attrs)) == 0 && NodesEqual(ae.Children, be.Children)
+}
+
+// nodeText returns the text of n if it is a text node.
+func nodeText(n Node) (string, bool) {
+	switch n := n.(type) {
+	case TextNode:
+		return string(n), true
+	case string:
+		return n, true
+	}
+	return "", false
+}
+
+// DiffNodes returns the changes turning the old tree into the new one, in document order.
+// Nodes that are equal in both trees are matched first; the remaining text nodes and elements are paired in order and
+// compared, elements with the same tag recursively, while the others are reported as deleted and inserted.
+func DiffNodes(old, new []Node) []Change {
+	var changes []Change
+	diffNodes(old, new, nil, nil, &changes)
+	return changes
+}
+
+func diffNodes(old, new []Node, oldPath, newPath []int, changes *[]Change) {
+	oldKeys, newKeys := nodeKeys(old, new)
+	matches := lcs(len(old), len(new), func(i, j int) bool { return oldKeys[i] == newKeys[j] })
+	i, j := 0, 0
+	for _, m := range append(matches, [2]int{len(old), len(new)}) {
+		// Pair the unmatched nodes between two matches, in order.
+		for ; i < m[0] && j < m[1] && similar(old[i], new[j]); i, j = i+1, j+1 {
+			diffNode(old[i], new[j], childPath(oldPath, i), childPath(newPath, j), changes)
+		}
+		for ; i < m[0]; i++ {
+			*changes = append(*changes, Change{Kind: ChangeDeleted, OldPath: childPath(oldPath, i), Old: old[i]})
+		}
+		for ; j < m[1]; j++ {
+			*changes = append(*changes, Change{Kind: ChangeInserted, NewPath: childPath(newPath, j), New: new[j]})
+		}
+		i, j = m[0]+1, m[1]+1
+	}
+}
+
+func diffNode(old, new Node, oldPath, newPath []int, changes *[]Change) {
+	if _, ok := nodeText(old); ok {
+		*changes = append(*changes, Change{Kind: ChangeText, OldPath: oldPath, NewPath: newPath, Old: old, New: new})
+		return
+	}
+	oe, ne := old.(*NodeElement), new.(*NodeElement)
+	if oe.Tag != ne.Tag {
+		*changes = append(*changes, Change{Kind: ChangeModified, OldPath: oldPath, NewPath: newPath, Old: old, New: new})
+		return
+	}
+	if attrs := diffAttrs(oe.Attrs, ne.Attrs); len(attrs) > 0 {
+		*changes = append(*changes, Change{Kind: ChangeAttrs, OldPath: oldPath, NewPath: newPath, Old: old, New: new, Attrs: attrs})
+	}
+	diffNodes(oe.Children, ne.Children, oldPath, newPath, changes)
+}
+
+// similar reports whether two nodes should be compared rather than reported as deleted and inserted, that is
+// whether both are text nodes or both are elements.
+func similar(a, b Node) bool {
+	_, aText := nodeText(a)
+	_, bText := nodeText(b)
+	if aText || bText {
+		return aText && bText
+	}
+	_, aok := a.(*NodeElement)
+	_, bok := b.(*NodeElement)
+	return aok && bok
+}
+
+func diffAttrs(a, b map[string]string) []string {
+	var keys []string
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			keys = append(keys, k)
+		}
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// nodeKeys returns keys for the nodes of a and b which are equal when the nodes are, so that every node is compared
+// once instead of once per pair. Elements are keyed by their encoding, in which TextNode and string text nodes are
+// the same and empty attributes and children are omitted, as NodesEqual considers them.
+func nodeKeys(a, b []Node) ([]int, []int) {
+	var (
+		ids    = map[string]int{}
+		unique = -1
+	)
+	keys := func(nodes []Node) []int {
+		keys := make([]int, len(nodes))
+		for i, n := range nodes {
+			var key string
+			if text, ok := nodeText(n); ok {
+				key = "t" + text
+			} else if e, ok := n.(*NodeElement); ok {
+				enc, err := encodeNodes(e)
+				if err != nil {
+					keys[i], unique = unique, unique-1
+					continue
+				}
+				key = "e" + string(enc)
+			} else if n == nil {
+				key = "n"
+			} else {
+				// Other values are never equal.
+				keys[i], unique = unique, unique-1
+				continue
+			}
+			id, ok := ids[key]
+			if !ok {
+				id = len(ids)
+				ids[key] = id
+			}
+			keys[i] = id
+		}
+		return keys
+	}
+	return keys(a), keys(b)
+}
+
+// lcs returns the index pairs of a longest common subsequence of two sequences of lengths n and m.
+// The common prefix and suffix are matched first, so that the table is only built for the part that differs.
+func lcs(n, m int, equal func(i, j int) bool) [][2]int {
+	var prefix, suffix int
+	for prefix < n && prefix < m && equal(prefix, prefix) {
+		prefix++
+	}
+	for suffix < n-prefix && suffix < m-prefix && equal(n-1-suffix, m-1-suffix) {
+		suffix++
+	}
+	pairs := make([][2]int, 0, prefix+suffix)
+	for k := 0; k < prefix; k++ {
+		pairs = append(pairs, [2]int{k, k})
+	}
+	for _, p := range lcsTable(n-prefix-suffix, m-prefix-suffix, func(i, j int) bool { return equal(prefix+i, prefix+j) }) {
+		pairs = append(pairs, [2]int{prefix + p[0], prefix + p[1]})
+	}
+	for k := suffix; k > 0; k-- {
+		pairs = append(pairs, [2]int{n - k, m - k})
+	}
+	return pairs
+}
+
+// lcsTable computes a longest common subsequence with the classic dynamic programming table.
+func lcsTable(n, m int, equal func(i, j int) bool) [][2]int {
+	table := make([][]int32, n+1)
+	for i := range table {
+		table[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case equal(i, j):
+				table[i][j] = table[i+1][j+1] + 1
+			case table[i+1][j] >= table[i][j+1]:
+				table[i][j] = table[i+1][j]
+			default:
+				table[i][j] = table[i][j+1]
+			}
+		}
+	}
+	var pairs [][2]int
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case equal(i, j):
+			pairs = append(pairs, [2]int{i, j})
+			i, j = i+1, j+1
+		case table[i+1][j] >= table[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
+
+func childPath(path []int, i int) []int {
+	return append(path[:len(path):len(path)], i)
+}
+
+func formatPath(path []int) string {
+	parts := make([]string, len(path))
+	for i, p := range path {
+		parts[i] = strconv.Itoa(p)
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+func describeNode(n Node) string {
+	if t, ok := nodeText(n); ok {
+		return strconv.Quote(t)
+	}
+	if e, ok := n.(*NodeElement); ok {
+		return "<" + e.Tag + ">"
+	}
+	return "nil"
+}
+
+// UnifiedDiff renders the differences between the old and new trees as a unified diff, with 3 lines of context.
+// Every element and text node is rendered on a line of its own, indented by its depth. Returns an empty string if both
+// trees are equal.
+func UnifiedDiff(old, new []Node) string {
+	a, b := diffLines(old, 0, nil), diffLines(new, 0, nil)
+	matches := lcs(len(a), len(b), func(i, j int) bool { return a[i] == b[j] })
+
+	// ops is the edit script: ' ' for kept lines, '-' for deleted ones and '+' for inserted ones.
+	type op struct {
+		kind byte
+		line string
+		i, j int
+	}
+	var ops []op
+	i, j := 0, 0
+	for _, m := range append(matches, [2]int{len(a), len(b)}) {
+		for ; i < m[0]; i++ {
+			ops = append(ops, op{'-', a[i], i, j})
+		}
+		for ; j < m[1]; j++ {
+			ops = append(ops, op{'+', b[j], i, j})
+		}
+		if m[0] < len(a) {
+			ops = append(ops, op{' ', a[i], i, j})
+		}
+		i, j = m[0]+1, m[1]+1
+	}
+
+	const context = 3
+	var sb strings.Builder
+	for k := 0; k < len(ops); {
+		if ops[k].kind == ' ' {
+			k++
+			continue
+		}
+		// Extend the hunk while changes are less than 2*context lines apart.
+		start := k - context
+		if start < 0 {
+			start = 0
+		}
+		end := k
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*context {
+				break
+			}
+			end = next
+		}
+		stop := end + context
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+
+		var oldCount, newCount int
+		for _, o := range ops[start:stop] {
+			if o.kind != '+' {
+				oldCount++
+			}
+			if o.kind != '-' {
+				newCount++
+			}
+		}
+		if sb.Len() == 0 {
+			sb.WriteString("--- old\n+++ new\n")
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(ops[start].i, oldCount), hunkRange(ops[start].j, newCount))
+		for _, o := range ops[start:stop] {
+			sb.WriteByte(o.kind)
+			sb.WriteString(o.line)
+			sb.WriteByte('\n')
+		}
+		k = stop
+	}
+	return sb.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return strconv.Itoa(start) + ",0"
+	}
+	if count == 1 {
+		return strconv.Itoa(start + 1)
+	}
+	return strconv.Itoa(start+1) + "," + strconv.Itoa(count)
+}
+
+// diffLines renders nodes as one line per node, indented by depth.
+func diffLines(nodes []Node, depth int, lines []string) []string {
+	indent := strings.Repeat("  ", depth)
+	for _, n := range nodes {
+		if t, ok := nodeText(n); ok {
+			lines = append(lines, indent+strconv.Quote(t))
+			continue
+		}
+		e, ok := n.(*NodeElement)
+		if !ok {
+			continue
+		}
+		keys := make([]string, 0, len(e.Attrs))
+		for k := range e.Attrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		line := indent + "<" + e.Tag
+		for _, k := range keys {
+			line += " " + k + "=" + strconv.Quote(e.Attrs[k])
+		}
+		lines = append(lines, line+">")
+		lines = diffLines(e.Children, depth+1, lines)
+	}
+	return lines
+}
@@ -0,0 +1,112 @@
+package telegraph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Sentinel errors for well-known Telegraph API error codes, usable with errors.Is:
+//
+//	if errors.Is(err, telegraph.ErrPageNotFound) { ... }
+var (
+	ErrAccessTokenInvalid  = &APIError{Code: "ACCESS_TOKEN_INVALID"}
+	ErrPageNotFound        = &APIError{Code: "PAGE_NOT_FOUND"}
+	ErrPageAccessDenied    = &APIError{Code: "PAGE_ACCESS_DENIED"}
+	ErrContentTooBig       = &APIError{Code: "CONTENT_TOO_BIG"}
+	ErrContentRequired     = &APIError{Code: "CONTENT_REQUIRED"}
+	ErrContentTextRequired = &APIError{Code: "CONTENT_TEXT_REQUIRED"}
+	ErrTitleRequired       = &APIError{Code: "TITLE_REQUIRED"}
+	ErrTitleTooLong        = &APIError{Code: "TITLE_TOO_LONG"}
+	ErrShortNameRequired   = &APIError{Code: "SHORT_NAME_REQUIRED"}
+	ErrAuthorNameTooLong   = &APIError{Code: "AUTHOR_NAME_TOO_LONG"}
+	ErrAuthorUrlInvalid    = &APIError{Code: "AUTHOR_URL_INVALID"}
+	ErrPathRequired        = &APIError{Code: "PATH_REQUIRED"}
+	ErrFloodWait           = &APIError{Code: "FLOOD_WAIT"}
+	ErrFileTooBig          = &APIError{Code: "File too big"}
+	ErrFileTypeInvalid     = &APIError{Code: "File type invalid"}
+)
+
+// APIError is returned when the Telegraph API answers a request with ok set to false.
+type APIError struct {
+	// Method is the name of the API method that failed, e.g. createPage.
+	Method string
+	// Description is the raw error string returned by Telegraph, e.g. FLOOD_WAIT_7.
+	Description string
+	// Code is the error code parsed from Description with any numeric argument stripped, e.g. FLOOD_WAIT.
+	Code string
+	// Argument is the numeric argument trailing the error code, if any (seconds to wait for FLOOD_WAIT_X).
+	Argument int
+}
+
+func newAPIError(method, description string) *APIError {
+	e := &APIError{
+		Method:      method,
+		Description: description,
+		Code:        description,
+	}
+	if i := strings.LastIndexByte(description, '_'); i > 0 {
+		if n, err := strconv.Atoi(description[i+1:]); err == nil {
+			e.Code = description[:i]
+			e.Argument = n
+		}
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("failed to %s: %s", e.Method, e.Description)
+}
+
+// Is reports whether target is an *APIError with the same Code, which makes the sentinel errors
+// of this package usable with errors.Is.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	return ok && t.Code == e.Code
+}
+
+// TransportError is returned when the HTTP request to Telegraph could not be executed.
+type TransportError struct {
+	// Method is the name of the API method that was being called.
+	Method string
+	// Err is the underlying error returned by the http client.
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("failed to execute POST request to %s: %s", e.Method, e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError is returned when the response of Telegraph could not be parsed.
+type DecodeError struct {
+	// Method is the name of the API method that was being called.
+	Method string
+	// Err is the underlying decoding error.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to parse response from %s: %s", e.Method, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPError is returned when Telegraph answers with a non-2xx HTTP status and no API error in the body.
+type HTTPError struct {
+	// Method is the name of the API method that was being called.
+	Method string
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Status is the HTTP status line of the response, e.g. "502 Bad Gateway".
+	Status string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("failed to %s: unexpected http status %s", e.Method, e.Status)
+}
@@ -3,3 +3,5 @@ module github.com/celestix/telegraph-go/v2
 go 1.18
 
 require golang.org/x/net v0.0.0-20210813160813-60bc85c4be6d
+
+require github.com/yuin/goldmark v1.4.12
@@ -1,9 +1,17 @@
 package telegraph
 
 import (
+	"context"
 	"net/http"
 )
 
+const (
+	// DefaultApiUrl is the URL of the Telegraph API used when ClientOpt.ApiUrl is empty.
+	DefaultApiUrl = "https://api.telegra.ph/"
+	// DefaultUploadUrl is the URL of the upload endpoint used when ClientOpt.UploadUrl is empty.
+	DefaultUploadUrl = "https://telegra.ph/upload"
+)
+
 // GetTelegraphClient returns a new TelegraphClient using the specified options.
 func GetTelegraphClient(options *ClientOpt) *TelegraphClient {
 	if options == nil {
@@ -13,11 +21,17 @@ func GetTelegraphClient(options *ClientOpt) *TelegraphClient {
 		options.HttpClient = http.DefaultClient
 	}
 	if options.ApiUrl == "" {
-		options.ApiUrl = "https://api.telegra.ph/"
+		options.ApiUrl = DefaultApiUrl
+	}
+	if options.UploadUrl == "" {
+		options.UploadUrl = DefaultUploadUrl
 	}
 	return &TelegraphClient{
-		HttpClient: options.HttpClient,
-		ApiUrl:     options.ApiUrl,
+		HttpClient:  options.HttpClient,
+		ApiUrl:      options.ApiUrl,
+		UploadUrl:   options.UploadUrl,
+		RetryPolicy: options.RetryPolicy,
+		RateLimiter: options.RateLimiter,
 	}
 }
 
@@ -26,37 +40,117 @@ func (a *Account) EditInfo(client *TelegraphClient, opts *EditAccountInfoOpts) (
 	return client.EditAccountInfo(a.AccessToken, opts)
 }
 
+// EditInfoCtx is like EditInfo but uses the provided context for the underlying HTTP request.
+func (a *Account) EditInfoCtx(ctx context.Context, client *TelegraphClient, opts *EditAccountInfoOpts) (*Account, error) {
+	return client.EditAccountInfoCtx(ctx, a.AccessToken, opts)
+}
+
 // GetInfo is a helper method to easily call GetAccountInfo by an account.
 func (a *Account) GetInfo(client *TelegraphClient) (*Account, error) {
 	return client.GetAccountInfo(a.AccessToken)
 }
 
+// GetInfoCtx is like GetInfo but uses the provided context for the underlying HTTP request.
+func (a *Account) GetInfoCtx(ctx context.Context, client *TelegraphClient) (*Account, error) {
+	return client.GetAccountInfoCtx(ctx, a.AccessToken)
+}
+
 // RevokeAccessToken is a helper method to easily call RevokeAccessToken by an account.
 func (a *Account) RevokeAccessToken(client *TelegraphClient) (*Account, error) {
 	return client.RevokeAccessToken(a.AccessToken)
 }
 
+// RevokeAccessTokenCtx is like RevokeAccessToken but uses the provided context for the underlying HTTP request.
+func (a *Account) RevokeAccessTokenCtx(ctx context.Context, client *TelegraphClient) (*Account, error) {
+	return client.RevokeAccessTokenCtx(ctx, a.AccessToken)
+}
+
 // CreatePage is a helper method to easily call CreatePage by an account.
 func (a *Account) CreatePage(client *TelegraphClient, title, content string, opts *PageOpts) (*Page, error) {
 	return client.CreatePage(a.AccessToken, title, content, opts)
 }
 
+// CreatePageCtx is like CreatePage but uses the provided context for the underlying HTTP request.
+func (a *Account) CreatePageCtx(ctx context.Context, client *TelegraphClient, title, content string, opts *PageOpts) (*Page, error) {
+	return client.CreatePageCtx(ctx, a.AccessToken, title, content, opts)
+}
+
 // EditPage is a helper method to easily call EditPage by an account with previous author_name and author_url.
 func (a *Account) EditPage(client *TelegraphClient, path, title, content string, opts *PageOpts) (*Page, error) {
 	return client.EditPage(a.AccessToken, path, title, content, opts)
 }
 
+// EditPageCtx is like EditPage but uses the provided context for the underlying HTTP request.
+func (a *Account) EditPageCtx(ctx context.Context, client *TelegraphClient, path, title, content string, opts *PageOpts) (*Page, error) {
+	return client.EditPageCtx(ctx, a.AccessToken, path, title, content, opts)
+}
+
+// CreatePageNodes is a helper method to easily call CreatePageNodes by an account.
+func (a *Account) CreatePageNodes(client *TelegraphClient, title string, content []Node, opts *PageOpts) (*Page, error) {
+	return client.CreatePageNodes(a.AccessToken, title, content, opts)
+}
+
+// CreatePageNodesCtx is like CreatePageNodes but uses the provided context for the underlying HTTP request.
+func (a *Account) CreatePageNodesCtx(ctx context.Context, client *TelegraphClient, title string, content []Node, opts *PageOpts) (*Page, error) {
+	return client.CreatePageNodesCtx(ctx, a.AccessToken, title, content, opts)
+}
+
+// EditPageNodes is a helper method to easily call EditPageNodes by an account.
+func (a *Account) EditPageNodes(client *TelegraphClient, path, title string, content []Node, opts *PageOpts) (*Page, error) {
+	return client.EditPageNodes(a.AccessToken, path, title, content, opts)
+}
+
+// EditPageNodesCtx is like EditPageNodes but uses the provided context for the underlying HTTP request.
+func (a *Account) EditPageNodesCtx(ctx context.Context, client *TelegraphClient, path, title string, content []Node, opts *PageOpts) (*Page, error) {
+	return client.EditPageNodesCtx(ctx, a.AccessToken, path, title, content, opts)
+}
+
+// PublishSeries is a helper method to easily call PublishSeries by an account.
+func (a *Account) PublishSeries(client *TelegraphClient, title string, content interface{}, opts *SeriesOpts) ([]Page, error) {
+	return client.PublishSeries(a.AccessToken, title, content, opts)
+}
+
+// PublishSeriesCtx is like PublishSeries but uses the provided context for the underlying HTTP requests.
+func (a *Account) PublishSeriesCtx(ctx context.Context, client *TelegraphClient, title string, content interface{}, opts *SeriesOpts) ([]Page, error) {
+	return client.PublishSeriesCtx(ctx, a.AccessToken, title, content, opts)
+}
+
+// PublishPage is a helper method to easily call PublishPage by an account.
+func (a *Account) PublishPage(client *TelegraphClient, path, title string, content interface{}, opts *PageOpts) (*Page, PublishAction, error) {
+	return client.PublishPage(a.AccessToken, path, title, content, opts)
+}
+
+// PublishPageCtx is like PublishPage but uses the provided context for the underlying HTTP requests.
+func (a *Account) PublishPageCtx(ctx context.Context, client *TelegraphClient, path, title string, content interface{}, opts *PageOpts) (*Page, PublishAction, error) {
+	return client.PublishPageCtx(ctx, a.AccessToken, path, title, content, opts)
+}
+
 // GetPageList is a helper method to easily call GetPageList by an account.
 func (a *Account) GetPageList(client *TelegraphClient, opts *PageListOpts) (*PageList, error) {
 	return client.GetPageList(a.AccessToken, opts)
 }
 
+// GetPageListCtx is like GetPageList but uses the provided context for the underlying HTTP request.
+func (a *Account) GetPageListCtx(ctx context.Context, client *TelegraphClient, opts *PageListOpts) (*PageList, error) {
+	return client.GetPageListCtx(ctx, a.AccessToken, opts)
+}
+
 // Get is a helper method to easily get page.
 func (p *Page) Get(client *TelegraphClient, returnContent bool) (*Page, error) {
 	return client.GetPage(p.Path, returnContent)
 }
 
+// GetCtx is like Get but uses the provided context for the underlying HTTP request.
+func (p *Page) GetCtx(ctx context.Context, client *TelegraphClient, returnContent bool) (*Page, error) {
+	return client.GetPageCtx(ctx, p.Path, returnContent)
+}
+
 // GetViews helper method to easily call GetViews in a page.
 func (p *Page) GetViews(client *TelegraphClient, opts *PageViewsOpts) (*PageViews, error) {
 	return client.GetViews(p.Path, opts)
 }
+
+// GetViewsCtx is like GetViews but uses the provided context for the underlying HTTP request.
+func (p *Page) GetViewsCtx(ctx context.Context, client *TelegraphClient, opts *PageViewsOpts) (*PageViews, error) {
+	return client.GetViewsCtx(ctx, p.Path, opts)
+}
@@ -0,0 +1,361 @@
+package telegraph
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// MarkdownFormat converts Markdown into the Telegraph content format, an array of Node.
+// CommonMark is supported along with the GitHub Flavored Markdown extensions (tables, strikethrough, autolinks and task
+// lists). Headings are mapped onto h3 and h4, code blocks become pre/code, images become figure/img/figcaption (or a
+// bare img when nested in a link or another inline element) and tables, which Telegraph does not support, are
+// rendered as aligned text in a pre block. Underlines are read from raw <u> tags, as written by RenderMarkdown.
+// The result can be passed to CreatePageNodes and EditPageNodes.
+// - data (type string, []byte or io.Reader): Markdown to convert.
+func MarkdownFormat(data interface{}) ([]Node, error) {
+	var source []byte
+
+	switch src := data.(type) {
+	case string:
+		source = []byte(src)
+	case []byte:
+		source = src
+	case io.Reader:
+		b, err := io.ReadAll(src)
+		if err != nil {
+			return nil, err
+		}
+		source = b
+	default:
+		return nil, errors.New("INVALID DATA TYPE")
+	}
+
+	doc := goldmark.New(goldmark.WithExtensions(extension.GFM)).Parser().Parse(text.NewReader(source))
+	c := &markdownConverter{source: source}
+	return c.blocks(doc)
+}
+
+type markdownConverter struct {
+	source []byte
+}
+
+func (c *markdownConverter) blocks(parent ast.Node) ([]Node, error) {
+	var nodes []Node
+	for n := parent.FirstChild(); n != nil; n = n.NextSibling() {
+		b, err := c.block(n)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, b...)
+	}
+	return nodes, nil
+}
+
+func (c *markdownConverter) block(n ast.Node) ([]Node, error) {
+	switch n := n.(type) {
+	case *ast.Heading:
+		tag := "h4"
+		if n.Level <= 2 {
+			tag = "h3"
+		}
+		return []Node{&NodeElement{Tag: tag, Children: c.inlines(n)}}, nil
+	case *ast.Paragraph:
+		return splitFigures(c.inlines(n)), nil
+	case *ast.TextBlock:
+		return c.inlines(n), nil
+	case *ast.ThematicBreak:
+		return []Node{&NodeElement{Tag: "hr"}}, nil
+	case *ast.CodeBlock, *ast.FencedCodeBlock:
+		return []Node{&NodeElement{Tag: "pre", Children: []Node{
+			&NodeElement{Tag: "code", Children: []Node{TextNode(strings.TrimSuffix(c.lines(n), "\n"))}},
+		}}}, nil
+	case *ast.Blockquote:
+		children, err := c.blocks(n)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&NodeElement{Tag: "blockquote", Children: flattenParagraphs(children)}}, nil
+	case *ast.List:
+		tag := "ul"
+		if n.IsOrdered() {
+			tag = "ol"
+		}
+		list := &NodeElement{Tag: tag}
+		for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+			children, err := c.blocks(item)
+			if err != nil {
+				return nil, err
+			}
+			list.Children = append(list.Children, &NodeElement{Tag: "li", Children: flattenParagraphs(children)})
+		}
+		return []Node{list}, nil
+	case *ast.HTMLBlock:
+		s := c.lines(n)
+		if n.HasClosure() {
+			s += string(n.ClosureLine.Value(c.source))
+		}
+		return ContentFormat(s)
+	case *east.Table:
+		return []Node{&NodeElement{Tag: "pre", Children: []Node{TextNode(c.table(n))}}}, nil
+	default:
+		return c.blocks(n)
+	}
+}
+
+func (c *markdownConverter) inlines(parent ast.Node) []Node {
+	return c.inlineRange(parent.FirstChild(), nil)
+}
+
+// inlineRange converts the sibling inline nodes from first up to, but excluding, end.
+func (c *markdownConverter) inlineRange(first, end ast.Node) []Node {
+	var nodes []Node
+	for n := first; n != nil && n != end; n = n.NextSibling() {
+		converted := c.inline(n)
+		// Markdown has no syntax for underlines, they are written as raw <u> and </u> tags around their content.
+		if c.rawHTML(n) == "<u>" {
+			if closing := c.closingTag(n, "</u>"); closing != nil {
+				converted = []Node{&NodeElement{Tag: "u", Children: c.inlineRange(n.NextSibling(), closing)}}
+				n = closing
+			}
+		}
+		for _, in := range converted {
+			// Text is split over several nodes by the parser, merge it back.
+			if t, ok := in.(TextNode); ok && len(nodes) > 0 {
+				if last, ok := nodes[len(nodes)-1].(TextNode); ok {
+					nodes[len(nodes)-1] = last + t
+					continue
+				}
+			}
+			nodes = append(nodes, in)
+		}
+	}
+	return nodes
+}
+
+func (c *markdownConverter) inline(n ast.Node) []Node {
+	switch n := n.(type) {
+	case *ast.Text:
+		nodes := []Node{TextNode(unescapeMarkdown(n.Segment.Value(c.source)))}
+		if n.HardLineBreak() {
+			nodes = append(nodes, &NodeElement{Tag: "br"})
+		} else if n.SoftLineBreak() {
+			nodes = append(nodes, TextNode(" "))
+		}
+		return nodes
+	case *ast.String:
+		return []Node{TextNode(n.Value)}
+	case *ast.CodeSpan:
+		var b strings.Builder
+		for t := n.FirstChild(); t != nil; t = t.NextSibling() {
+			if t, ok := t.(*ast.Text); ok {
+				b.WriteString(strings.ReplaceAll(string(t.Segment.Value(c.source)), "\n", " "))
+			}
+		}
+		return []Node{&NodeElement{Tag: "code", Children: []Node{TextNode(b.String())}}}
+	case *ast.Emphasis:
+		tag := "em"
+		if n.Level >= 2 {
+			tag = "strong"
+		}
+		return []Node{&NodeElement{Tag: tag, Children: c.inlines(n)}}
+	case *east.Strikethrough:
+		return []Node{&NodeElement{Tag: "s", Children: c.inlines(n)}}
+	case *ast.Link:
+		return []Node{link(string(n.Destination), c.inlines(n))}
+	case *ast.AutoLink:
+		return []Node{link(string(n.URL(c.source)), []Node{TextNode(n.Label(c.source))})}
+	case *ast.Image:
+		img := &NodeElement{Tag: "img"}
+		if src := string(n.Destination); safeURL(src, nil) {
+			img.Attrs = map[string]string{"src": src}
+		}
+		// Figures are blocks, so images nested in links, emphasis or headings are kept inline.
+		switch n.Parent().(type) {
+		case *ast.Paragraph, *ast.TextBlock:
+		default:
+			return []Node{img}
+		}
+		figure := &NodeElement{Tag: "figure", Children: []Node{img}}
+		caption := string(n.Title)
+		if caption == "" {
+			caption = nodesText(c.inlines(n))
+		}
+		if caption != "" {
+			figure.Children = append(figure.Children, &NodeElement{Tag: "figcaption", Children: []Node{TextNode(caption)}})
+		}
+		return []Node{figure}
+	case *ast.RawHTML:
+		// Inline HTML tags are split over several nodes and cannot be converted on their own, only line breaks are kept
+		// here and underlines by inlineRange.
+		switch c.rawHTML(n) {
+		case "<br>", "<br/>":
+			return []Node{&NodeElement{Tag: "br"}}
+		}
+		return nil
+	case *east.TaskCheckBox:
+		if n.IsChecked {
+			return []Node{TextNode("☑ ")}
+		}
+		return []Node{TextNode("☐ ")}
+	default:
+		return c.inlines(n)
+	}
+}
+
+// rawHTML returns the lowercase tag of an inline HTML node without spaces, or an empty string for other nodes.
+func (c *markdownConverter) rawHTML(n ast.Node) string {
+	raw, ok := n.(*ast.RawHTML)
+	if !ok {
+		return ""
+	}
+	var b bytes.Buffer
+	for i := 0; i < raw.Segments.Len(); i++ {
+		s := raw.Segments.At(i)
+		b.Write(s.Value(c.source))
+	}
+	return strings.ToLower(strings.ReplaceAll(b.String(), " ", ""))
+}
+
+// closingTag returns the first following sibling of n which is the given inline HTML tag, or nil.
+func (c *markdownConverter) closingTag(n ast.Node, tag string) ast.Node {
+	for n = n.NextSibling(); n != nil; n = n.NextSibling() {
+		if c.rawHTML(n) == tag {
+			return n
+		}
+	}
+	return nil
+}
+
+// lines returns the raw content of a block made of lines, like code blocks.
+func (c *markdownConverter) lines(n ast.Node) string {
+	var b strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		s := lines.At(i)
+		b.Write(s.Value(c.source))
+	}
+	return b.String()
+}
+
+// table renders a table as text with aligned columns.
+func (c *markdownConverter) table(t *east.Table) string {
+	var (
+		rows   [][]string
+		widths []int
+	)
+	for row := t.FirstChild(); row != nil; row = row.NextSibling() {
+		var cells []string
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			s := nodesText(c.inlines(cell))
+			if len(cells) >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if w := utf8.RuneCountInString(s); w > widths[len(cells)] {
+				widths[len(cells)] = w
+			}
+			cells = append(cells, s)
+		}
+		rows = append(rows, cells)
+	}
+
+	var b strings.Builder
+	for i, cells := range rows {
+		if i == 1 {
+			for j, w := range widths {
+				if j > 0 {
+					b.WriteString("-+-")
+				}
+				b.WriteString(strings.Repeat("-", w))
+			}
+			b.WriteByte('\n')
+		}
+		for j, s := range cells {
+			if j > 0 {
+				b.WriteString(" | ")
+			}
+			b.WriteString(s)
+			if j < len(cells)-1 {
+				b.WriteString(strings.Repeat(" ", widths[j]-utf8.RuneCountInString(s)))
+			}
+		}
+		if i < len(rows)-1 {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+func link(href string, children []Node) *NodeElement {
+	a := &NodeElement{Tag: "a", Children: children}
+	if safeURL(href, nil) {
+		a.Attrs = map[string]string{"href": href}
+	}
+	return a
+}
+
+// splitFigures wraps the inline nodes of a paragraph in p elements, keeping figures out of them.
+func splitFigures(inlines []Node) []Node {
+	var (
+		nodes []Node
+		p     *NodeElement
+	)
+	for _, n := range inlines {
+		if e, ok := n.(*NodeElement); ok && e.Tag == "figure" {
+			nodes, p = append(nodes, e), nil
+			continue
+		}
+		if p == nil {
+			p = &NodeElement{Tag: "p"}
+			nodes = append(nodes, p)
+		}
+		p.Children = append(p.Children, n)
+	}
+	return nodes
+}
+
+// flattenParagraphs replaces the paragraphs of blocks with their children, separated by line breaks, as Telegraph
+// does not render paragraphs inside blockquotes and list items.
+func flattenParagraphs(blocks []Node) []Node {
+	var nodes []Node
+	for i, n := range blocks {
+		if e, ok := n.(*NodeElement); ok && e.Tag == "p" {
+			if i > 0 {
+				nodes = append(nodes, &NodeElement{Tag: "br"})
+			}
+			nodes = append(nodes, e.Children...)
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+// nodesText returns the concatenated text of nodes.
+func nodesText(nodes []Node) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case TextNode:
+			b.WriteString(string(n))
+		case string:
+			b.WriteString(n)
+		case *NodeElement:
+			b.WriteString(nodesText(n.Children))
+		}
+	}
+	return b.String()
+}
+
+func unescapeMarkdown(b []byte) string {
+	return string(util.ResolveEntityNames(util.ResolveNumericReferences(util.UnescapePunctuations(b))))
+}
@@ -2,13 +2,13 @@ package telegraph
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
-	"mime/multipart"
-	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 )
 
@@ -19,6 +19,11 @@ import (
 // - opts (type CreateAccountOpts): All optional parameters.
 // https://telegra.ph/api#createAccount
 func (c *TelegraphClient) CreateAccount(shortName string, opts *CreateAccountOpts) (*Account, error) {
+	return c.CreateAccountCtx(context.Background(), shortName, opts)
+}
+
+// CreateAccountCtx is like CreateAccount but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) CreateAccountCtx(ctx context.Context, shortName string, opts *CreateAccountOpts) (*Account, error) {
 	var (
 		u = url.Values{}
 		a Account
@@ -30,7 +35,7 @@ func (c *TelegraphClient) CreateAccount(shortName string, opts *CreateAccountOpt
 		u.Add("author_url", opts.AuthorUrl)
 	}
 
-	r, err := c.InvokeRequest("createAccount", u)
+	r, err := c.InvokeRequestCtx(ctx, "createAccount", u)
 	if err != nil {
 		return nil, err
 	}
@@ -44,6 +49,11 @@ func (c *TelegraphClient) CreateAccount(shortName string, opts *CreateAccountOpt
 // - opts (type EditAccountInfoOpts): All optional parameters.
 // https://telegra.ph/api#editAccountInfo
 func (c *TelegraphClient) EditAccountInfo(accessToken string, opts *EditAccountInfoOpts) (*Account, error) {
+	return c.EditAccountInfoCtx(context.Background(), accessToken, opts)
+}
+
+// EditAccountInfoCtx is like EditAccountInfo but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) EditAccountInfoCtx(ctx context.Context, accessToken string, opts *EditAccountInfoOpts) (*Account, error) {
 	var (
 		u = url.Values{}
 		a Account
@@ -61,7 +71,7 @@ func (c *TelegraphClient) EditAccountInfo(accessToken string, opts *EditAccountI
 		}
 	}
 
-	r, err := c.InvokeRequest("editAccountInfo", u)
+	r, err := c.InvokeRequestCtx(ctx, "editAccountInfo", u)
 	if err != nil {
 		return nil, err
 	}
@@ -74,6 +84,11 @@ func (c *TelegraphClient) EditAccountInfo(accessToken string, opts *EditAccountI
 // - accessToken (type string): Access token of the Telegraph account.
 // https://telegra.ph/api#getAccountInfo
 func (c *TelegraphClient) GetAccountInfo(accessToken string) (*Account, error) {
+	return c.GetAccountInfoCtx(context.Background(), accessToken)
+}
+
+// GetAccountInfoCtx is like GetAccountInfo but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) GetAccountInfoCtx(ctx context.Context, accessToken string) (*Account, error) {
 	var (
 		u = url.Values{}
 		a Account
@@ -81,7 +96,7 @@ func (c *TelegraphClient) GetAccountInfo(accessToken string) (*Account, error) {
 	u.Add("access_token", accessToken)
 	u.Add("fields", `["short_name", "author_name", "author_url", "auth_url", "page_count"]`)
 
-	r, err := c.InvokeRequest("getAccountInfo", u)
+	r, err := c.InvokeRequestCtx(ctx, "getAccountInfo", u)
 	if err != nil {
 		return nil, err
 	}
@@ -94,13 +109,18 @@ func (c *TelegraphClient) GetAccountInfo(accessToken string) (*Account, error) {
 // - accessToken (type string): Access token of the Telegraph account.
 // https://telegra.ph/api#revokeAccessToken
 func (c *TelegraphClient) RevokeAccessToken(accessToken string) (*Account, error) {
+	return c.RevokeAccessTokenCtx(context.Background(), accessToken)
+}
+
+// RevokeAccessTokenCtx is like RevokeAccessToken but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) RevokeAccessTokenCtx(ctx context.Context, accessToken string) (*Account, error) {
 	var (
 		u = url.Values{}
 		a Account
 	)
 	u.Add("access_token", accessToken)
 
-	r, err := c.InvokeRequest("revokeAccessToken", u)
+	r, err := c.InvokeRequestCtx(ctx, "revokeAccessToken", u)
 	if err != nil {
 		return nil, err
 	}
@@ -116,17 +136,39 @@ func (c *TelegraphClient) RevokeAccessToken(accessToken string) (*Account, error
 // - opts (type PageOpts): All optional parameters.
 // https://telegra.ph/api#createPage
 func (c *TelegraphClient) CreatePage(accessToken string, title string, content string, opts *PageOpts) (*Page, error) {
+	return c.CreatePageCtx(context.Background(), accessToken, title, content, opts)
+}
+
+// CreatePageCtx is like CreatePage but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) CreatePageCtx(ctx context.Context, accessToken string, title string, content string, opts *PageOpts) (*Page, error) {
+	cNode, err := ContentFormat(content)
+	if err != nil {
+		return nil, err
+	}
+	return c.CreatePageNodesCtx(ctx, accessToken, title, cNode, opts)
+}
+
+// CreatePageNodes creates a new telegraph page from an already built Node tree.
+// Unlike CreatePage, the content is not parsed as HTML but sent as it is.
+// On success, returns a Page object.
+// - accessToken (type string): Access token of the Telegraph account.
+// - title (type string): Page title.
+// - content (type []Node): Content of the page (up to 64 KB converted into a json string).
+// - opts (type PageOpts): All optional parameters.
+// https://telegra.ph/api#createPage
+func (c *TelegraphClient) CreatePageNodes(accessToken string, title string, content []Node, opts *PageOpts) (*Page, error) {
+	return c.CreatePageNodesCtx(context.Background(), accessToken, title, content, opts)
+}
+
+// CreatePageNodesCtx is like CreatePageNodes but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) CreatePageNodesCtx(ctx context.Context, accessToken string, title string, content []Node, opts *PageOpts) (*Page, error) {
 	var (
 		u = url.Values{}
 		a Page
 	)
 	u.Add("access_token", accessToken)
 	u.Add("title", title)
-	cNode, err := ContentFormat(content)
-	if err != nil {
-		return nil, err
-	}
-	cNodeB, err := json.Marshal(cNode)
+	cNodeB, err := encodeContent(content)
 	if err != nil {
 		return nil, err
 	}
@@ -138,7 +180,7 @@ func (c *TelegraphClient) CreatePage(accessToken string, title string, content s
 		u.Add("return_content", strconv.FormatBool(opts.ReturnContent))
 	}
 
-	r, err := c.InvokeRequest("createPage", u)
+	r, err := c.InvokeRequestCtx(ctx, "createPage", u)
 	if err != nil {
 		return nil, err
 	}
@@ -155,6 +197,33 @@ func (c *TelegraphClient) CreatePage(accessToken string, title string, content s
 // - opts (type PageOpts): All optional parameters.
 // https://telegra.ph/api#editPage
 func (c *TelegraphClient) EditPage(accessToken, path, title, content string, opts *PageOpts) (*Page, error) {
+	return c.EditPageCtx(context.Background(), accessToken, path, title, content, opts)
+}
+
+// EditPageCtx is like EditPage but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) EditPageCtx(ctx context.Context, accessToken, path, title, content string, opts *PageOpts) (*Page, error) {
+	cNode, err := ContentFormat(content)
+	if err != nil {
+		return nil, err
+	}
+	return c.EditPageNodesCtx(ctx, accessToken, path, title, cNode, opts)
+}
+
+// EditPageNodes edits an existing Telegraph page with an already built Node tree.
+// Unlike EditPage, the content is not parsed as HTML but sent as it is.
+// On success, returns a Page object.
+// - accessToken (type string): Access token of the Telegraph account.
+// - path (type string): Path to the page.
+// - title (type string): Page title.
+// - content (type []Node): Content of the page (up to 64 KB converted into a json string).
+// - opts (type PageOpts): All optional parameters.
+// https://telegra.ph/api#editPage
+func (c *TelegraphClient) EditPageNodes(accessToken, path, title string, content []Node, opts *PageOpts) (*Page, error) {
+	return c.EditPageNodesCtx(context.Background(), accessToken, path, title, content, opts)
+}
+
+// EditPageNodesCtx is like EditPageNodes but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) EditPageNodesCtx(ctx context.Context, accessToken, path, title string, content []Node, opts *PageOpts) (*Page, error) {
 	var (
 		u = url.Values{}
 		a Page
@@ -163,12 +232,7 @@ func (c *TelegraphClient) EditPage(accessToken, path, title, content string, opt
 	u.Add("path", path)
 	u.Add("title", title)
 
-	cNode, err := ContentFormat(content)
-	if err != nil {
-		return nil, err
-	}
-
-	cNodeB, err := json.Marshal(cNode)
+	cNodeB, err := encodeContent(content)
 	if err != nil {
 		return nil, err
 	}
@@ -180,7 +244,7 @@ func (c *TelegraphClient) EditPage(accessToken, path, title, content string, opt
 		u.Add("return_content", strconv.FormatBool(opts.ReturnContent))
 	}
 
-	r, err := c.InvokeRequest("editPage", u)
+	r, err := c.InvokeRequestCtx(ctx, "editPage", u)
 	if err != nil {
 		return nil, err
 	}
@@ -194,6 +258,11 @@ func (c *TelegraphClient) EditPage(accessToken, path, title, content string, opt
 // - returnContent (type bool): If true, content field will be returned in Page object.
 // https://telegra.ph/api#getPage
 func (c *TelegraphClient) GetPage(path string, returnContent bool) (*Page, error) {
+	return c.GetPageCtx(context.Background(), path, returnContent)
+}
+
+// GetPageCtx is like GetPage but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) GetPageCtx(ctx context.Context, path string, returnContent bool) (*Page, error) {
 	var (
 		u = url.Values{}
 		a Page
@@ -201,7 +270,7 @@ func (c *TelegraphClient) GetPage(path string, returnContent bool) (*Page, error
 	u.Add("path", path)
 	u.Add("return_content", strconv.FormatBool(returnContent))
 
-	r, err := c.InvokeRequest("getPage", u)
+	r, err := c.InvokeRequestCtx(ctx, "getPage", u)
 	if err != nil {
 		return nil, err
 	}
@@ -215,6 +284,11 @@ func (c *TelegraphClient) GetPage(path string, returnContent bool) (*Page, error
 // - opts
 // https://telegra.ph/api#getPageList
 func (c *TelegraphClient) GetPageList(accessToken string, opts *PageListOpts) (*PageList, error) {
+	return c.GetPageListCtx(context.Background(), accessToken, opts)
+}
+
+// GetPageListCtx is like GetPageList but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) GetPageListCtx(ctx context.Context, accessToken string, opts *PageListOpts) (*PageList, error) {
 	var (
 		u = url.Values{}
 		a PageList
@@ -229,7 +303,7 @@ func (c *TelegraphClient) GetPageList(accessToken string, opts *PageListOpts) (*
 		}
 	}
 
-	r, err := c.InvokeRequest("getPageList", u)
+	r, err := c.InvokeRequestCtx(ctx, "getPageList", u)
 	if err != nil {
 		return nil, err
 	}
@@ -243,6 +317,11 @@ func (c *TelegraphClient) GetPageList(accessToken string, opts *PageListOpts) (*
 // - opts (type PageViewsOpts): All optional parameters.
 // https://telegra.ph/api#getViews
 func (c *TelegraphClient) GetViews(path string, opts *PageViewsOpts) (*PageViews, error) {
+	return c.GetViewsCtx(context.Background(), path, opts)
+}
+
+// GetViewsCtx is like GetViews but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) GetViewsCtx(ctx context.Context, path string, opts *PageViewsOpts) (*PageViews, error) {
 	var (
 		u = url.Values{}
 		a PageViews
@@ -256,7 +335,7 @@ func (c *TelegraphClient) GetViews(path string, opts *PageViewsOpts) (*PageViews
 		u.Add("hour", strconv.FormatInt(opts.Hour, 10))
 	}
 
-	r, err := c.InvokeRequest("getViews", u)
+	r, err := c.InvokeRequestCtx(ctx, "getViews", u)
 	if err != nil {
 		return nil, err
 	}
@@ -270,80 +349,158 @@ func (c *TelegraphClient) GetViews(path string, opts *PageViewsOpts) (*PageViews
 // - filePath (type string): location of the file to upload to Telegraph.
 // https://telegra.ph/upload
 func (c *TelegraphClient) UploadFile(filePath string) (string, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+	return c.UploadFileCtx(context.Background(), filePath)
+}
+
+// UploadFileCtx is like UploadFile but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) UploadFileCtx(ctx context.Context, filePath string) (string, error) {
+	return c.UploadFileWithOptsCtx(ctx, filePath, nil)
+}
+
+// UploadFileWithOpts is like UploadFile but takes optional parameters.
+// - filePath (type string): location of the file to upload to Telegraph.
+// - opts (type UploadOpts): All optional parameters, e.g. a Progress callback.
+func (c *TelegraphClient) UploadFileWithOpts(filePath string, opts *UploadOpts) (string, error) {
+	return c.UploadFileWithOptsCtx(context.Background(), filePath, opts)
+}
+
+// UploadFileWithOptsCtx is like UploadFileWithOpts but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) UploadFileWithOptsCtx(ctx context.Context, filePath string, opts *UploadOpts) (string, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return "", err
 	}
-	part, err := writer.CreateFormFile("file", filePath)
-	if err != nil {
-		return "", err
-	}
-	if _, err = io.Copy(part, file); err != nil {
-		return "", err
-	}
-	if err = writer.Close(); err != nil {
-		return "", err
-	}
-
-	return c.doUploadFile(writer.FormDataContentType(), body)
+	defer file.Close()
+	return c.UploadReaderCtx(ctx, file, filepath.Base(filePath), opts)
 }
 
 // UploadFileByBytes uploads a file to Telegraph by bytes.
 // Use this method to upload a file to Telegraph.
 // (You can upload some specific file formats like .jpg, .jpeg, .png, .gif, etc only)
 // Returns a path to the uploaded file i.e. everything that comes after https://telegra.ph/
-// - filePath (type string): location of the file to upload to Telegraph.
+// - content (type []byte): content of the file to upload to Telegraph.
 // https://telegra.ph/upload
 func (c *TelegraphClient) UploadFileByBytes(content []byte) (string, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	part, err := writer.CreateFormFile("file", "file_name")
-	if err != nil {
-		return "", err
-	}
-	if _, err = io.Copy(part, bytes.NewReader(content)); err != nil {
-		return "", err
-	}
-	if err = writer.Close(); err != nil {
-		return "", err
-	}
+	return c.UploadFileByBytesCtx(context.Background(), content)
+}
 
-	return c.doUploadFile(writer.FormDataContentType(), body)
+// UploadFileByBytesCtx is like UploadFileByBytes but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) UploadFileByBytesCtx(ctx context.Context, content []byte) (string, error) {
+	return c.UploadFileByBytesWithOptsCtx(ctx, content, nil)
 }
 
-// UploadFileByBytes uploads a file to Telegraph by bytes.
-// Use this method to upload a file to Telegraph.
-// (You can upload some specific file formats like .jpg, .jpeg, .png, .gif, etc only)
+// UploadFileByBytesWithOpts is like UploadFileByBytes but takes optional parameters.
+// - content (type []byte): content of the file to upload to Telegraph.
+// - opts (type UploadOpts): All optional parameters, e.g. a Progress callback.
+func (c *TelegraphClient) UploadFileByBytesWithOpts(content []byte, opts *UploadOpts) (string, error) {
+	return c.UploadFileByBytesWithOptsCtx(context.Background(), content, opts)
+}
+
+// UploadFileByBytesWithOptsCtx is like UploadFileByBytesWithOpts but uses the provided context for the underlying HTTP
+// request.
+func (c *TelegraphClient) UploadFileByBytesWithOptsCtx(ctx context.Context, content []byte, opts *UploadOpts) (string, error) {
+	return c.UploadReaderCtx(ctx, bytes.NewReader(content), "", opts)
+}
+
+// UploadReader uploads a file read from r to Telegraph.
+// The file is streamed to Telegraph without being buffered. Its content type is detected from its first 512 bytes
+// unless opts.ContentType is set, and it is rejected with a FileTypeError before anything is sent if Telegraph does
+// not accept it. Files bigger than the size limit are rejected with a FileTooBigError, before anything is sent if r
+// is seekable or has a Len method (like *bytes.Reader), or as soon as the limit is crossed otherwise.
+// Uploads are only retried if r is seekable, as it has to be read again.
 // Returns a path to the uploaded file i.e. everything that comes after https://telegra.ph/
-// - filePath (type string): location of the file to upload to Telegraph.
+// - r (type io.Reader): content of the file to upload to Telegraph.
+// - name (type string): name of the file, derived from its content type if empty.
+// - opts (type UploadOpts): All optional parameters.
 // https://telegra.ph/upload
-func (c *TelegraphClient) doUploadFile(contentType string, body io.Reader) (string, error) {
-	request, err := http.NewRequest(http.MethodPost, "https://telegra.ph/upload", body)
-	if err != nil {
-		return "", err
-	}
-	request.Header.Set("Content-Type", contentType)
+func (c *TelegraphClient) UploadReader(r io.Reader, name string, opts *UploadOpts) (string, error) {
+	return c.UploadReaderCtx(context.Background(), r, name, opts)
+}
 
-	httpResponse, err := c.HttpClient.Do(request)
+// UploadReaderCtx is like UploadReader but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) UploadReaderCtx(ctx context.Context, r io.Reader, name string, opts *UploadOpts) (string, error) {
+	f, err := newUploadFile(r, name, opts)
 	if err != nil {
 		return "", err
 	}
-
-	b, err := io.ReadAll(httpResponse.Body)
+	paths, err := c.upload(ctx, []*uploadFile{f}, opts.progress())
+	var filesErr *UploadFilesError
+	if errors.As(err, &filesErr) {
+		return "", filesErr.Errors[0]
+	}
 	if err != nil {
 		return "", err
 	}
+	return paths[0], nil
+}
 
-	var rUpload []Upload
-	if err := json.Unmarshal(b, &rUpload); err != nil {
-		m := map[string]string{}
-		if err := json.Unmarshal(b, &m); err != nil {
-			return "", err
+// UploadFiles uploads several files to Telegraph in a single request.
+// Files are checked and streamed like with UploadReader.
+// Returns the paths to the uploaded files, in the order of filePaths. If Telegraph rejected some of the files, the
+// paths of the others are returned along with an UploadFilesError.
+// - filePaths (type ...string): locations of the files to upload to Telegraph.
+// https://telegra.ph/upload
+func (c *TelegraphClient) UploadFiles(filePaths ...string) ([]string, error) {
+	return c.UploadFilesCtx(context.Background(), filePaths...)
+}
+
+// UploadFilesCtx is like UploadFiles but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) UploadFilesCtx(ctx context.Context, filePaths ...string) ([]string, error) {
+	return c.UploadFilesWithOptsCtx(ctx, filePaths, nil)
+}
+
+// UploadFilesWithOpts is like UploadFiles but takes optional parameters.
+// - filePaths (type []string): locations of the files to upload to Telegraph.
+// - opts (type UploadOpts): All optional parameters, e.g. a Progress callback.
+func (c *TelegraphClient) UploadFilesWithOpts(filePaths []string, opts *UploadOpts) ([]string, error) {
+	return c.UploadFilesWithOptsCtx(context.Background(), filePaths, opts)
+}
+
+// UploadFilesWithOptsCtx is like UploadFilesWithOpts but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) UploadFilesWithOptsCtx(ctx context.Context, filePaths []string, opts *UploadOpts) ([]string, error) {
+	sources := make([]UploadSource, len(filePaths))
+	for i, filePath := range filePaths {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
 		}
-		return "", fmt.Errorf("failed to upload: %s", m["error"])
+		defer file.Close()
+		sources[i] = UploadSource{Reader: file, Name: filepath.Base(filePath)}
 	}
+	return c.UploadReadersCtx(ctx, sources, opts)
+}
 
-	return rUpload[0].Path, nil
+// UploadReaders uploads several files read from readers to Telegraph in a single request.
+// Files are checked and streamed like with UploadReader, and the request is only retried if all the readers are
+// seekable.
+// Returns the paths to the uploaded files, in the order of files. If Telegraph rejected some of the files, the
+// paths of the others are returned along with an UploadFilesError.
+// - files (type []UploadSource): files to upload to Telegraph.
+// - opts (type UploadOpts): All optional parameters, applied to every file.
+// https://telegra.ph/upload
+func (c *TelegraphClient) UploadReaders(files []UploadSource, opts *UploadOpts) ([]string, error) {
+	return c.UploadReadersCtx(context.Background(), files, opts)
+}
+
+// UploadReadersCtx is like UploadReaders but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) UploadReadersCtx(ctx context.Context, files []UploadSource, opts *UploadOpts) ([]string, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	uploads := make([]*uploadFile, len(files))
+	for i, source := range files {
+		fileOpts := UploadOpts{ContentType: source.ContentType}
+		if opts != nil {
+			fileOpts = *opts
+			if source.ContentType != "" {
+				fileOpts.ContentType = source.ContentType
+			}
+		}
+		f, err := newUploadFile(source.Reader, source.Name, &fileOpts)
+		if err != nil {
+			return nil, err
+		}
+		uploads[i] = f
+	}
+	return c.upload(ctx, uploads, opts.progress())
 }
@@ -0,0 +1,91 @@
+package telegraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// TextNode represents a DOM text node.
+type TextNode string
+
+// UnmarshalJSON decodes a NodeElement, turning its children into TextNode and *NodeElement values.
+func (n *NodeElement) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Tag      string            `json:"tag"`
+		Attrs    map[string]string `json:"attrs"`
+		Children []json.RawMessage `json:"children"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	children, err := decodeNodes(raw.Children)
+	if err != nil {
+		return err
+	}
+	n.Tag, n.Attrs, n.Children = raw.Tag, raw.Attrs, children
+	return nil
+}
+
+// UnmarshalJSON decodes a Page, turning its content into TextNode and *NodeElement values.
+func (p *Page) UnmarshalJSON(data []byte) error {
+	type page Page
+	var raw struct {
+		*page
+		Content []json.RawMessage `json:"content"`
+	}
+	raw.page = (*page)(p)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	content, err := decodeNodes(raw.Content)
+	if err != nil {
+		return err
+	}
+	p.Content = content
+	return nil
+}
+
+// DecodeNodes decodes a JSON array of Telegraph nodes, as found in the content field of a Page, into TextNode and
+// *NodeElement values.
+func DecodeNodes(data []byte) ([]Node, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return decodeNodes(raw)
+}
+
+func decodeNodes(raw []json.RawMessage) ([]Node, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	nodes := make([]Node, 0, len(raw))
+	for _, r := range raw {
+		n, err := decodeNode(r)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+func decodeNode(raw json.RawMessage) (Node, error) {
+	switch b := bytes.TrimSpace(raw); {
+	case len(b) > 0 && b[0] == '"':
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return nil, err
+		}
+		return TextNode(s), nil
+	case len(b) > 0 && b[0] == '{':
+		n := new(NodeElement)
+		if err := json.Unmarshal(b, n); err != nil {
+			return nil, err
+		}
+		return n, nil
+	default:
+		return nil, fmt.Errorf("invalid node: %s", b)
+	}
+}
@@ -0,0 +1,105 @@
+package telegraph
+
+import (
+	"context"
+	"errors"
+)
+
+// PublishAction is what PublishPage did to publish a page.
+type PublishAction int
+
+const (
+	// PublishUnchanged means the page was already up to date and was left untouched.
+	PublishUnchanged PublishAction = iota
+	// PublishCreated means a new page was created.
+	PublishCreated
+	// PublishUpdated means the existing page was edited.
+	PublishUpdated
+)
+
+func (a PublishAction) String() string {
+	switch a {
+	case PublishCreated:
+		return "created"
+	case PublishUpdated:
+		return "updated"
+	default:
+		return "unchanged"
+	}
+}
+
+// PublishPage makes sure the page at path has the given title, author and content, and only edits it when something
+// differs, which saves requests (and rate limit budget) when republishing unchanged pages.
+// The current page is fetched with its content and compared after normalization (adjacent text nodes are merged and
+// empty ones dropped). A new page is created if path is empty or no page exists at path.
+// Returns the published page and what was done to publish it.
+// - accessToken (type string): Access token of the Telegraph account.
+// - path (type string): Path to the page, empty to create a new page.
+// - title (type string): Page title.
+// - content (type []Node, string, []byte or io.Reader): Content of the page, HTML is converted with ContentFormat.
+// - opts (type PageOpts): All optional parameters. AuthorName and AuthorUrl are compared as well when set, as Telegraph
+// fills them in from the account when they are empty.
+func (c *TelegraphClient) PublishPage(accessToken, path, title string, content interface{}, opts *PageOpts) (*Page, PublishAction, error) {
+	return c.PublishPageCtx(context.Background(), accessToken, path, title, content, opts)
+}
+
+// PublishPageCtx is like PublishPage but uses the provided context for the underlying HTTP requests.
+func (c *TelegraphClient) PublishPageCtx(ctx context.Context, accessToken, path, title string, content interface{}, opts *PageOpts) (*Page, PublishAction, error) {
+	nodes, ok := content.([]Node)
+	if !ok {
+		var err error
+		if nodes, err = ContentFormat(content); err != nil {
+			return nil, PublishUnchanged, err
+		}
+	}
+	if opts == nil {
+		opts = &PageOpts{}
+	}
+
+	if path != "" {
+		current, err := c.GetPageCtx(ctx, path, true)
+		switch {
+		case errors.Is(err, ErrPageNotFound):
+		case err != nil:
+			return nil, PublishUnchanged, err
+		case current.Title == title && sameAuthor(current, opts) && NodesEqual(normalizeNodes(current.Content), normalizeNodes(nodes)):
+			return current, PublishUnchanged, nil
+		default:
+			page, err := c.EditPageNodesCtx(ctx, accessToken, path, title, nodes, opts)
+			if err != nil {
+				return nil, PublishUnchanged, err
+			}
+			return page, PublishUpdated, nil
+		}
+	}
+
+	page, err := c.CreatePageNodesCtx(ctx, accessToken, title, nodes, opts)
+	if err != nil {
+		return nil, PublishUnchanged, err
+	}
+	return page, PublishCreated, nil
+}
+
+// sameAuthor reports whether page has the author fields set in opts, empty fields match any value.
+func sameAuthor(page *Page, opts *PageOpts) bool {
+	return (opts.AuthorName == "" || page.AuthorName == opts.AuthorName) &&
+		(opts.AuthorUrl == "" || page.AuthorUrl == opts.AuthorUrl)
+}
+
+// normalizeNodes returns a copy of nodes with adjacent text nodes merged and empty text nodes dropped.
+func normalizeNodes(nodes []Node) []Node {
+	var out []Node
+	for _, n := range nodes {
+		if t, ok := nodeText(n); ok {
+			if t != "" {
+				out = appendText(out, TextNode(t))
+			}
+			continue
+		}
+		if e, ok := n.(*NodeElement); ok {
+			n = &NodeElement{Tag: e.Tag, Attrs: e.Attrs, Children: normalizeNodes(e.Children)}
+		}
+		out = append(out, n)
+	}
+	return out
+}
@@ -0,0 +1,206 @@
+package telegraph
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Rate is the budget of a token bucket.
+type Rate struct {
+	// Limit is the number of requests allowed per second. Zero means no limit.
+	Limit float64
+	// Burst is the maximum number of requests that can be sent at once. (default = 1)
+	Burst int
+}
+
+// RateLimiterOpts is the options used to construct a RateLimiter.
+type RateLimiterOpts struct {
+	// Global is the budget shared by all requests of the client.
+	Global Rate
+	// PerMethod is the budget of each Telegraph API method, keyed by method name (e.g. createPage).
+	// Uploads use the "upload" key.
+	PerMethod map[string]Rate
+	// PerAccessToken is the budget of every single access token.
+	PerAccessToken Rate
+}
+
+// RateLimiter throttles the requests of a TelegraphClient with token buckets. It is safe for concurrent
+// use, and a single RateLimiter may be shared by several clients.
+// When Telegraph answers with FLOOD_WAIT_X, the limiter holds back all requests for X seconds and drains the
+// buckets involved in the call, so that requests resume at the configured rate.
+type RateLimiter struct {
+	opts RateLimiterOpts
+
+	mu          sync.Mutex
+	global      *bucket
+	methods     map[string]*bucket
+	tokens      map[string]*bucket
+	lastSweep   time.Time
+	pausedUntil time.Time
+}
+
+// sweepInterval is how often the buckets of idle access tokens are evicted.
+const sweepInterval = time.Minute
+
+// NewRateLimiter returns a new RateLimiter using the specified options.
+func NewRateLimiter(opts RateLimiterOpts) *RateLimiter {
+	return &RateLimiter{
+		opts:    opts,
+		global:  newBucket(opts.Global),
+		methods: map[string]*bucket{},
+		tokens:  map[string]*bucket{},
+	}
+}
+
+// Wait blocks until a request to method with accessToken is allowed by all budgets, or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context, method, accessToken string) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.sweep(now)
+		buckets := l.buckets(method, accessToken)
+		d := l.pausedUntil.Sub(now)
+		for _, b := range buckets {
+			if bd := b.delay(now); bd > d {
+				d = bd
+			}
+		}
+		if d <= 0 {
+			for _, b := range buckets {
+				b.take()
+			}
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// FloodWait holds back all requests for d and drains the buckets of method and accessToken.
+// It is called by the client whenever Telegraph answers with FLOOD_WAIT_X.
+func (l *RateLimiter) FloodWait(method, accessToken string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if until := now.Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	for _, b := range l.buckets(method, accessToken) {
+		b.drain(now)
+	}
+}
+
+// sweep evicts the buckets of access tokens which have refilled since their last request, as they are the same as
+// new ones. It runs at most once per sweepInterval so that the memory used by the limiter follows the number of
+// recently active access tokens; l.mu must be held.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for token, b := range l.tokens {
+		if b.full(now) {
+			delete(l.tokens, token)
+		}
+	}
+}
+
+// buckets returns the buckets that apply to a request; l.mu must be held.
+func (l *RateLimiter) buckets(method, accessToken string) []*bucket {
+	buckets := make([]*bucket, 0, 3)
+	if l.global != nil {
+		buckets = append(buckets, l.global)
+	}
+	if r, ok := l.opts.PerMethod[method]; ok {
+		b, ok := l.methods[method]
+		if !ok {
+			b = newBucket(r)
+			l.methods[method] = b
+		}
+		if b != nil {
+			buckets = append(buckets, b)
+		}
+	}
+	if accessToken != "" && l.opts.PerAccessToken.Limit > 0 {
+		b, ok := l.tokens[accessToken]
+		if !ok {
+			b = newBucket(l.opts.PerAccessToken)
+			l.tokens[accessToken] = b
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets
+}
+
+// wait waits for the rate limiter of the client, if any.
+func (c *TelegraphClient) wait(ctx context.Context, method, accessToken string) error {
+	if c.RateLimiter == nil {
+		return nil
+	}
+	return c.RateLimiter.Wait(ctx, method, accessToken)
+}
+
+// observe reports FLOOD_WAIT errors to the rate limiter of the client, if any.
+func (c *TelegraphClient) observe(method, accessToken string, err error) {
+	var apiErr *APIError
+	if c.RateLimiter != nil && errors.As(err, &apiErr) && apiErr.Code == ErrFloodWait.Code {
+		c.RateLimiter.FloodWait(method, accessToken, time.Duration(apiErr.Argument)*time.Second)
+	}
+}
+
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(r Rate) *bucket {
+	if r.Limit <= 0 {
+		return nil
+	}
+	burst := float64(r.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &bucket{rate: r.Limit, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// delay refills the bucket and returns how long until a token is available.
+func (b *bucket) delay(now time.Time) time.Duration {
+	if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// full reports whether the bucket has refilled up to its burst at now.
+func (b *bucket) full(now time.Time) bool {
+	return b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst
+}
+
+func (b *bucket) take() {
+	b.tokens--
+}
+
+func (b *bucket) drain(now time.Time) {
+	b.tokens = 0
+	b.last = now
+}
@@ -0,0 +1,162 @@
+package telegraph
+
+import (
+	"html"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+)
+
+// voidTags is the set of supported tags that have no closing tag.
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+// RenderHTML renders nodes as HTML. Text and attribute values are escaped, void elements (br, hr and img) have no
+// closing tag, and the elements and attributes that Telegraph does not support are left out, keeping their children,
+// so that content fetched from Telegraph can be safely embedded in a web page.
+func RenderHTML(nodes []Node) string {
+	var b strings.Builder
+	renderHTML(&b, nodes)
+	return b.String()
+}
+
+// HTML renders the content of the page as HTML, see RenderHTML.
+// The page must have been fetched with its content, e.g. with GetPage(path, true).
+func (p *Page) HTML() string {
+	return RenderHTML(p.Content)
+}
+
+func renderHTML(b *strings.Builder, nodes []Node) {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case TextNode:
+			b.WriteString(html.EscapeString(string(n)))
+		case string:
+			b.WriteString(html.EscapeString(n))
+		case *NodeElement:
+			if !supportedTags[n.Tag] {
+				renderHTML(b, n.Children)
+				continue
+			}
+			b.WriteString("<" + n.Tag)
+			keys := make([]string, 0, len(n.Attrs))
+			for k := range n.Attrs {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				if supportedAttrs[k][n.Tag] && safeURL(n.Attrs[k], nil) {
+					b.WriteString(" " + k + `="` + html.EscapeString(n.Attrs[k]) + `"`)
+				}
+			}
+			b.WriteString(">")
+			if voidTags[n.Tag] {
+				continue
+			}
+			// Parsers drop a newline right after <pre>, so a leading newline of the text has to be doubled.
+			if n.Tag == "pre" && startsWithNewline(n.Children) {
+				b.WriteString("\n")
+			}
+			renderHTML(b, n.Children)
+			b.WriteString("</" + n.Tag + ">")
+		}
+	}
+}
+
+// startsWithNewline reports whether the first node of nodes is text starting with a newline.
+func startsWithNewline(nodes []Node) bool {
+	if len(nodes) == 0 {
+		return false
+	}
+	switch t := nodes[0].(type) {
+	case TextNode:
+		return strings.HasPrefix(string(t), "\n")
+	case string:
+		return strings.HasPrefix(t, "\n")
+	}
+	return false
+}
+
+// HTMLDocumentOpts is the optional parameters for RenderHTMLDocument.
+type HTMLDocumentOpts struct {
+	// Date of publication displayed next to the author. Hidden if zero.
+	Date time.Time
+	// Stylesheet replaces the default style sheet, which mimics the look of telegra.ph articles.
+	Stylesheet string
+}
+
+// RenderHTMLDocument renders the page as a standalone HTML document styled like a telegra.ph article, with its title,
+// author and optionally its date of publication.
+// - page (type *Page): Page to render, fetched with its content.
+// - opts (type HTMLDocumentOpts): All optional parameters.
+func RenderHTMLDocument(page *Page, opts *HTMLDocumentOpts) (string, error) {
+	if opts == nil {
+		opts = &HTMLDocumentOpts{}
+	}
+	stylesheet := opts.Stylesheet
+	if stylesheet == "" {
+		stylesheet = defaultStylesheet
+	}
+	var date string
+	if !opts.Date.IsZero() {
+		date = opts.Date.Format("January 2, 2006")
+	}
+
+	var b strings.Builder
+	err := documentTemplate.Execute(&b, map[string]interface{}{
+		"Page":       page,
+		"Date":       date,
+		"DateTime":   opts.Date.Format(time.RFC3339),
+		"Stylesheet": template.CSS(stylesheet),
+		"Content":    template.HTML(RenderHTML(page.Content)),
+	})
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+var documentTemplate = template.Must(template.New("document").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Page.Title}}</title>
+{{- with .Page.Description}}
+<meta name="description" content="{{.}}">
+{{- end}}
+<style>{{.Stylesheet}}</style>
+</head>
+<body>
+<article class="tl_article">
+<h1>{{.Page.Title}}</h1>
+<address>
+{{- if .Page.AuthorName}}{{if .Page.AuthorUrl}}<a rel="author" href="{{.Page.AuthorUrl}}">{{.Page.AuthorName}}</a>{{else}}{{.Page.AuthorName}}{{end}}{{end}}
+{{- if .Date}}{{if .Page.AuthorName}} · {{end}}<time datetime="{{.DateTime}}">{{.Date}}</time>{{end -}}
+</address>
+{{.Content}}
+</article>
+</body>
+</html>
+`))
+
+const defaultStylesheet = `
+body { margin: 0; background: #fff; color: #000; }
+.tl_article { max-width: 732px; margin: 0 auto; padding: 21px 21px 50px; font-family: Georgia, serif; font-size: 18px; line-height: 1.58; }
+.tl_article h1 { font-family: 'Lora', 'Times New Roman', serif; font-size: 32px; line-height: 1.17; font-weight: 700; margin: 0 0 12px; }
+.tl_article address { font-family: 'Helvetica Neue', Arial, sans-serif; font-size: 15px; font-style: normal; color: #79828b; margin-bottom: 24px; }
+.tl_article address a { color: #79828b; }
+.tl_article h3 { font-family: 'Helvetica Neue', Arial, sans-serif; font-size: 24px; line-height: 30px; margin: 32px 0 12px; }
+.tl_article h4 { font-family: 'Helvetica Neue', Arial, sans-serif; font-size: 19px; line-height: 23px; margin: 32px 0 12px; }
+.tl_article p { margin: 0 0 12px; }
+.tl_article a { color: inherit; text-decoration: underline; }
+.tl_article blockquote { border-left: 3px solid #000; margin: 16px 0; padding: 0 20px; font-style: italic; }
+.tl_article aside { text-align: center; font-style: italic; margin: 16px 0; padding: 0 20px; }
+.tl_article figure { margin: 16px 0; text-align: center; }
+.tl_article img, .tl_article video, .tl_article iframe { max-width: 100%; }
+.tl_article figcaption { font-size: 15px; color: #79828b; padding: 10px 0; }
+.tl_article pre { background: #f5f5f5; padding: 10px 15px; white-space: pre-wrap; font-size: 15px; }
+.tl_article code { font-family: Menlo, Consolas, monospace; font-size: 15px; }
+.tl_article hr { border: none; text-align: center; margin: 32px 0; }
+.tl_article hr:after { content: '***'; letter-spacing: 8px; color: #79828b; }
+`
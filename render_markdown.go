@@ -0,0 +1,272 @@
+package telegraph
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// RenderMarkdown renders nodes as Markdown (CommonMark with the GitHub Flavored Markdown extensions).
+// The output converts back to the same nodes with MarkdownFormat for everything Markdown can express: h3 and h4 become
+// level 2 and 3 headings, line breaks in headings become spaces, underlines and asides are kept as HTML, and embedded
+// iframes and videos become links.
+func RenderMarkdown(nodes []Node) string {
+	return strings.Join(markdownBlocks(nodes), "\n\n") + "\n"
+}
+
+// Markdown renders the content of the page as Markdown, see RenderMarkdown.
+// The page must have been fetched with its content, e.g. with GetPage(path, true).
+func (p *Page) Markdown() string {
+	return RenderMarkdown(p.Content)
+}
+
+// markdownBlocks renders every block of nodes, consecutive inline nodes are grouped into paragraphs.
+func markdownBlocks(nodes []Node) []string {
+	var (
+		blocks []string
+		inline []Node
+	)
+	flush := func() {
+		if s := strings.TrimSpace(markdownInline(inline)); s != "" {
+			blocks = append(blocks, s)
+		}
+		inline = nil
+	}
+	for _, n := range nodes {
+		e, ok := n.(*NodeElement)
+		if !ok || !isMarkdownBlock(e) {
+			inline = append(inline, n)
+			continue
+		}
+		flush()
+		if s := markdownBlock(e); s != "" {
+			blocks = append(blocks, s)
+		}
+	}
+	flush()
+	return blocks
+}
+
+func isMarkdownBlock(e *NodeElement) bool {
+	return blockTags[e.Tag] && e.Tag != "li"
+}
+
+func markdownBlock(e *NodeElement) string {
+	switch e.Tag {
+	case "p":
+		return strings.TrimSpace(markdownInline(e.Children))
+	case "h3":
+		return "## " + markdownHeading(e.Children)
+	case "h4":
+		return "### " + markdownHeading(e.Children)
+	case "hr":
+		return "---"
+	case "pre":
+		code := nodesText(e.Children)
+		fence := "```"
+		for strings.Contains(code, fence) {
+			fence += "`"
+		}
+		return fence + "\n" + strings.TrimSuffix(code, "\n") + "\n" + fence
+	case "blockquote":
+		return prefixLines(strings.Join(markdownBlocks(e.Children), "\n\n"), "> ", ">")
+	case "aside":
+		return RenderHTML([]Node{e})
+	case "ul", "ol":
+		var items []string
+		n := 1
+		for _, child := range e.Children {
+			li, ok := child.(*NodeElement)
+			if !ok || li.Tag != "li" {
+				continue
+			}
+			marker := "- "
+			if e.Tag == "ol" {
+				marker = strconv.Itoa(n) + ". "
+				n++
+			}
+			// Continuation lines are indented to the content of the item.
+			item := strings.Join(markdownBlocks(li.Children), "\n")
+			items = append(items, marker+strings.ReplaceAll(item, "\n", "\n"+strings.Repeat(" ", len(marker))))
+		}
+		return strings.Join(items, "\n")
+	case "figure":
+		var (
+			media   *NodeElement
+			caption string
+		)
+		for _, child := range e.Children {
+			c, ok := child.(*NodeElement)
+			if !ok {
+				continue
+			}
+			if c.Tag == "figcaption" {
+				caption = strings.TrimSpace(nodesText(c.Children))
+			} else if media == nil {
+				media = c
+			}
+		}
+		if media == nil {
+			return escapeMarkdown(caption)
+		}
+		return markdownMedia(media, caption)
+	case "img", "iframe", "video":
+		return markdownMedia(e, "")
+	default:
+		return strings.Join(markdownBlocks(e.Children), "\n\n")
+	}
+}
+
+// markdownMedia renders an image as a Markdown image and embeds as a link to the embedded content.
+func markdownMedia(e *NodeElement, caption string) string {
+	src := e.Attrs["src"]
+	if e.Tag == "img" {
+		return "![" + escapeMarkdown(caption) + "](" + markdownURL(src) + ")"
+	}
+	// Embeds point to telegra.ph/embed/<service>?url=<original url>, link to the original content instead.
+	if strings.HasPrefix(src, "/embed/") {
+		if u, err := url.Parse(src); err == nil && u.Query().Get("url") != "" {
+			src = u.Query().Get("url")
+		}
+	}
+	if strings.HasPrefix(src, "/") {
+		src = "https://telegra.ph" + src
+	}
+	if caption == "" {
+		caption = src
+	}
+	return "[" + escapeMarkdown(caption) + "](" + markdownURL(src) + ")"
+}
+
+// markdownHeading renders the content of a heading, which must fit on a single line: line breaks become spaces.
+func markdownHeading(nodes []Node) string {
+	return strings.TrimSpace(strings.ReplaceAll(markdownInline(unbreak(nodes)), "\n", " "))
+}
+
+// unbreak returns a copy of nodes with br elements replaced by spaces.
+func unbreak(nodes []Node) []Node {
+	out := make([]Node, len(nodes))
+	for i, n := range nodes {
+		if e, ok := n.(*NodeElement); ok {
+			if e.Tag == "br" {
+				n = TextNode(" ")
+			} else {
+				n = &NodeElement{Tag: e.Tag, Attrs: e.Attrs, Children: unbreak(e.Children)}
+			}
+		}
+		out[i] = n
+	}
+	return out
+}
+
+func markdownInline(nodes []Node) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case TextNode:
+			b.WriteString(escapeMarkdown(string(n)))
+		case string:
+			b.WriteString(escapeMarkdown(n))
+		case *NodeElement:
+			b.WriteString(markdownInlineElement(n))
+		}
+	}
+	return b.String()
+}
+
+func markdownInlineElement(e *NodeElement) string {
+	switch e.Tag {
+	case "b", "strong":
+		return wrapMarkdown("**", markdownInline(e.Children))
+	case "i", "em":
+		return wrapMarkdown("*", markdownInline(e.Children))
+	case "s":
+		return wrapMarkdown("~~", markdownInline(e.Children))
+	case "u":
+		return "<u>" + markdownInline(e.Children) + "</u>"
+	case "code":
+		code := nodesText(e.Children)
+		fence := "`"
+		for strings.Contains(code, fence) {
+			fence += "`"
+		}
+		if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+			code = " " + code + " "
+		}
+		return fence + code + fence
+	case "a":
+		text := markdownInline(e.Children)
+		if href, ok := e.Attrs["href"]; ok {
+			return "[" + text + "](" + markdownURL(href) + ")"
+		}
+		return text
+	case "br":
+		return "\\\n"
+	case "img", "iframe", "video", "figure":
+		return markdownBlock(e)
+	default:
+		return markdownInline(e.Children)
+	}
+}
+
+// wrapMarkdown wraps s with the emphasis delimiter, moving surrounding spaces out of it as Markdown requires.
+func wrapMarkdown(delim, s string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	lead := s[:strings.Index(s, trimmed)]
+	trail := s[len(lead)+len(trimmed):]
+	return lead + delim + trimmed + delim + trail
+}
+
+// escapeMarkdown escapes the characters of text that would otherwise be interpreted as Markdown.
+func escapeMarkdown(text string) string {
+	var b strings.Builder
+	lineStart := true
+	for i, r := range text {
+		switch r {
+		case '\\', '`', '*', '_', '[', ']', '<', '>', '~', '|', '&':
+			b.WriteByte('\\')
+		case '#', '-', '+', '=':
+			if lineStart {
+				b.WriteByte('\\')
+			}
+		case '.', ')':
+			// An ordered list marker is a number at the start of a line followed by a dot or a parenthesis.
+			if j := strings.LastIndexByte(text[:i], '\n') + 1; isDigits(text[j:i]) && i > j {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteRune(r)
+		lineStart = r == '\n' || (lineStart && r == ' ')
+	}
+	return b.String()
+}
+
+// markdownURL escapes the characters of a link destination that would end it.
+func markdownURL(u string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E").Replace(u)
+}
+
+// prefixLines prefixes every line of s, empty lines get emptyPrefix instead.
+func prefixLines(s, prefix, emptyPrefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if l == "" {
+			lines[i] = emptyPrefix
+		} else {
+			lines[i] = prefix + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,7 @@
 package telegraph
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -18,15 +19,34 @@ type Body struct {
 	Result json.RawMessage `json:"result"`
 }
 
+// InvokeRequest sends a request to the given Telegraph API method and returns the raw result.
 func (c *TelegraphClient) InvokeRequest(method string, params url.Values) (json.RawMessage, error) {
-	r, err := http.NewRequest(http.MethodPost, c.ApiUrl+method, strings.NewReader(params.Encode()))
+	return c.InvokeRequestCtx(context.Background(), method, params)
+}
+
+// InvokeRequestCtx is like InvokeRequest but uses the provided context for the underlying HTTP request.
+func (c *TelegraphClient) InvokeRequestCtx(ctx context.Context, method string, params url.Values) (res json.RawMessage, err error) {
+	accessToken := params.Get("access_token")
+	err = c.withRetry(ctx, func() error {
+		if err := c.wait(ctx, method, accessToken); err != nil {
+			return err
+		}
+		res, err = c.invokeRequest(ctx, method, params)
+		c.observe(method, accessToken, err)
+		return err
+	})
+	return res, err
+}
+
+func (c *TelegraphClient) invokeRequest(ctx context.Context, method string, params url.Values) (json.RawMessage, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiUrl()+method, strings.NewReader(params.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to build POST request to %s: %w", method, err)
 	}
 
 	resp, err := c.HttpClient.Do(r)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute POST request to %s: %w", method, err)
+		return nil, &TransportError{Method: method, Err: err}
 	}
 
 	defer func() {
@@ -35,10 +55,34 @@ func (c *TelegraphClient) InvokeRequest(method string, params url.Values) (json.
 
 	var b Body
 	if err = json.NewDecoder(resp.Body).Decode(&b); err != nil {
-		return nil, fmt.Errorf("failed to parse response from %s: %w", method, err)
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return nil, &HTTPError{Method: method, StatusCode: resp.StatusCode, Status: resp.Status}
+		}
+		return nil, &DecodeError{Method: method, Err: err}
 	}
 	if !b.Ok {
-		return nil, fmt.Errorf("failed to %s: %s", method, b.Error)
+		if b.Error == "" && (resp.StatusCode < 200 || resp.StatusCode > 299) {
+			return nil, &HTTPError{Method: method, StatusCode: resp.StatusCode, Status: resp.Status}
+		}
+		return nil, newAPIError(method, b.Error)
 	}
 	return b.Result, nil
 }
+
+// apiUrl returns the URL of the Telegraph API, falling back to DefaultApiUrl for clients not built by
+// GetTelegraphClient.
+func (c *TelegraphClient) apiUrl() string {
+	if c.ApiUrl == "" {
+		return DefaultApiUrl
+	}
+	return c.ApiUrl
+}
+
+// uploadUrl returns the URL of the upload endpoint, falling back to DefaultUploadUrl for clients not built by
+// GetTelegraphClient.
+func (c *TelegraphClient) uploadUrl() string {
+	if c.UploadUrl == "" {
+		return DefaultUploadUrl
+	}
+	return c.UploadUrl
+}
@@ -0,0 +1,142 @@
+package telegraph
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures how the TelegraphClient retries failed requests.
+// FLOOD_WAIT_X errors are retried after sleeping X seconds, transport errors and 5xx responses of idempotent methods
+// are retried after an exponential backoff. All other errors are returned immediately.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts per call, including the first one. (default = 3)
+	MaxAttempts int
+	// MaxWait caps the total time spent sleeping between attempts of a single call; a call whose next
+	// wait would exceed it fails with the last error instead. Zero means no limit.
+	MaxWait time.Duration
+	// Backoff is the delay before the first retry of a transport error or 5xx response, it is doubled
+	// for each following retry. (default = 500ms)
+	Backoff time.Duration
+	// MaxBackoff caps the delay computed from Backoff. Zero means no limit.
+	MaxBackoff time.Duration
+	// Jitter is the fraction of every delay, between 0 and 1, that is randomized to spread retries of
+	// concurrent callers apart. FLOOD_WAIT_X delays are only lengthened, never shortened below X seconds.
+	Jitter float64
+	// RetryNonIdempotent also retries transport errors and 5xx responses of createAccount, revokeAccessToken,
+	// createPage and uploads. Telegraph may have processed the failed request, so a retry may create a duplicate
+	// account, page or file, or revoke the new access token.
+	RetryNonIdempotent bool
+}
+
+// idempotentMethods are the methods which have no further effect when sent again, and are therefore safely retried
+// when it is unknown whether Telegraph processed them.
+var idempotentMethods = map[string]bool{
+	"editAccountInfo": true,
+	"getAccountInfo":  true,
+	"editPage":        true,
+	"getPage":         true,
+	"getPageList":     true,
+	"getViews":        true,
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts < 1 {
+		return 3
+	}
+	return p.MaxAttempts
+}
+
+// delay returns how long to wait before retrying after err at the given attempt (starting at 1),
+// and whether err should be retried at all.
+func (p *RetryPolicy) delay(err error, attempt int) (time.Duration, bool) {
+	var (
+		apiErr   *APIError
+		httpErr  *HTTPError
+		transErr *TransportError
+		d        time.Duration
+		// low is the lower bound of the jitter, relative to its upper bound.
+		low = -1.0
+	)
+	switch {
+	case errors.As(err, &apiErr):
+		if apiErr.Code != ErrFloodWait.Code {
+			return 0, false
+		}
+		d = time.Duration(apiErr.Argument) * time.Second
+		// Telegraph refuses requests sent before X seconds, so the jitter is only ever added.
+		low = 0
+	case errors.As(err, &httpErr) && httpErr.StatusCode >= http.StatusInternalServerError,
+		errors.As(err, &transErr):
+		var method string
+		if httpErr != nil {
+			method = httpErr.Method
+		} else {
+			method = transErr.Method
+		}
+		if !p.RetryNonIdempotent && !idempotentMethods[method] {
+			return 0, false
+		}
+		d = p.Backoff
+		if d <= 0 {
+			d = 500 * time.Millisecond
+		}
+		for i := 1; i < attempt && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+			d *= 2
+		}
+		if p.MaxBackoff > 0 && d > p.MaxBackoff {
+			d = p.MaxBackoff
+		}
+	default:
+		return 0, false
+	}
+	if p.Jitter > 0 {
+		j := p.Jitter
+		if j > 1 {
+			j = 1
+		}
+		d += time.Duration(j * float64(d) * (low + rand.Float64()*(1-low)))
+	}
+	return d, true
+}
+
+// withRetry calls do until it succeeds or the RetryPolicy of the client gives up.
+func (c *TelegraphClient) withRetry(ctx context.Context, do func() error) error {
+	p := c.RetryPolicy
+	if p == nil {
+		return do()
+	}
+	var waited time.Duration
+	for attempt := 1; ; attempt++ {
+		err := do()
+		if err == nil || attempt >= p.maxAttempts() {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctxError(ctx, err)
+		}
+		d, ok := p.delay(err, attempt)
+		if !ok || (p.MaxWait > 0 && waited+d > p.MaxWait) {
+			return err
+		}
+		waited += d
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctxError(ctx, err)
+		case <-t.C:
+		}
+	}
+}
+
+// ctxError returns err if it already reports why ctx is done, or else ctx.Err(), so that callers can check for
+// context.Canceled and context.DeadlineExceeded.
+func ctxError(ctx context.Context, err error) error {
+	if errors.Is(err, ctx.Err()) {
+		return err
+	}
+	return ctx.Err()
+}
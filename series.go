@@ -0,0 +1,255 @@
+package telegraph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SeriesOpts is the optional parameters for PublishSeries.
+type SeriesOpts struct {
+	// Optional. Name of the author, displayed below the title.
+	AuthorName string
+	// Optional. Profile link, opened when users click on the author's name below the title.
+	AuthorUrl string
+	// Optional. Paths of the pages of a previously published series, in order, as returned by PublishSeries. These
+	// pages are edited instead of being created again, so that re-publishing a series updates it instead of
+	// duplicating it. When empty, a new series is created.
+	Paths []string
+	// Optional. Format of the title of every part but the first one, given the title and the part number.
+	// (default = "%s (part %d)")
+	PartTitleFormat string
+	// Optional. Text of the link to the next part. (default = "Next part")
+	NextPartText string
+	// Optional. Text of the link to the previous part. (default = "Previous part")
+	PreviousPartText string
+}
+
+// navReserve is the room left in each part for the navigation links, whose paths are unknown when splitting.
+const navReserve = 1024
+
+// splittableTags are the blocks which SplitContent splits between their children when they do not fit in a part on
+// their own.
+var splittableTags = map[string]bool{"ul": true, "ol": true, "blockquote": true}
+
+// SplitContent splits nodes at block boundaries into parts whose encoded size does not exceed limit bytes.
+// Top-level nodes are kept whole, except lists and quotes too big for a single part, which are split between their
+// items or children (the items of an ordered list split this way are numbered from 1 again in every part).
+// It returns a ContentTooBigError if another top-level node, or a single item, does not fit in limit.
+func SplitContent(nodes []Node, limit int) ([][]Node, error) {
+	var (
+		parts [][]Node
+		part  []Node
+		size  = 2
+	)
+	add := func(n Node, b []byte) {
+		if len(part) > 0 && size+1+len(b) > limit {
+			parts = append(parts, part)
+			part, size = nil, 2
+		}
+		if len(part) > 0 {
+			size++
+		}
+		part = append(part, n)
+		size += len(b)
+	}
+	for i, n := range nodes {
+		b, err := encodeNodes(n)
+		if err != nil {
+			return nil, err
+		}
+		if len(b)+2 <= limit {
+			add(n, b)
+			continue
+		}
+		pieces, err := splitBlock(n, limit-2)
+		if err != nil {
+			return nil, err
+		}
+		if pieces == nil {
+			return nil, &ContentTooBigError{Size: len(b) + 2, Limit: limit, Node: i}
+		}
+		for _, piece := range pieces {
+			pb, err := encodeNodes(piece)
+			if err != nil {
+				return nil, err
+			}
+			add(piece, pb)
+		}
+	}
+	if len(part) > 0 || len(parts) == 0 {
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// splitBlock splits a block of splittableTags into copies holding consecutive runs of its children, each encoded in
+// at most limit bytes. It returns nil if n cannot be split or one of its children does not fit in limit.
+func splitBlock(n Node, limit int) ([]Node, error) {
+	e, ok := n.(*NodeElement)
+	if !ok || !splittableTags[e.Tag] {
+		return nil, nil
+	}
+	// The size of a copy is that of the copy without children plus the children separated by commas.
+	empty, err := encodeNodes(&NodeElement{Tag: e.Tag, Attrs: e.Attrs, Children: []Node{TextNode("")}})
+	if err != nil {
+		return nil, err
+	}
+	base := len(empty) - len(`""`)
+
+	var (
+		pieces   []Node
+		children []Node
+		size     int
+	)
+	for _, child := range e.Children {
+		b, err := encodeNodes(child)
+		if err != nil {
+			return nil, err
+		}
+		if base+len(b) > limit {
+			return nil, nil
+		}
+		if len(children) > 0 && size+1+len(b) > limit {
+			pieces = append(pieces, &NodeElement{Tag: e.Tag, Attrs: e.Attrs, Children: children})
+			children = nil
+		}
+		if len(children) == 0 {
+			size = base + len(b)
+		} else {
+			size += 1 + len(b)
+		}
+		children = append(children, child)
+	}
+	if len(children) > 0 {
+		pieces = append(pieces, &NodeElement{Tag: e.Tag, Attrs: e.Attrs, Children: children})
+	}
+	return pieces, nil
+}
+
+// PublishSeries publishes content as a series of pages linked to each other with "Previous part" and "Next part"
+// links, splitting it at block boundaries with SplitContent so that every page fits in MaxContentSize.
+// The pages given in SeriesOpts.Paths, usually those returned by an earlier run, are edited instead of being created
+// again; pages of an earlier run beyond the new number of parts are left untouched.
+// Returns the pages of the series, in order. If publishing a part fails, the pages published so far are returned along
+// with the error; pass their paths as SeriesOpts.Paths to resume the series without duplicating them.
+// - accessToken (type string): Access token of the Telegraph account.
+// - title (type string): Title of the first page of the series.
+// - content (type []Node, string, []byte or io.Reader): Content of the series, HTML is converted with ContentFormat.
+// - opts (type SeriesOpts): All optional parameters.
+func (c *TelegraphClient) PublishSeries(accessToken, title string, content interface{}, opts *SeriesOpts) ([]Page, error) {
+	return c.PublishSeriesCtx(context.Background(), accessToken, title, content, opts)
+}
+
+// PublishSeriesCtx is like PublishSeries but uses the provided context for the underlying HTTP requests.
+func (c *TelegraphClient) PublishSeriesCtx(ctx context.Context, accessToken, title string, content interface{}, opts *SeriesOpts) ([]Page, error) {
+	if opts == nil {
+		opts = &SeriesOpts{}
+	}
+	nodes, ok := content.([]Node)
+	if !ok {
+		var err error
+		if nodes, err = ContentFormat(content); err != nil {
+			return nil, err
+		}
+	}
+	parts, err := SplitContent(nodes, MaxContentSize-navReserve)
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make([]string, len(parts))
+	for i := range parts {
+		titles[i] = opts.partTitle(title, i)
+	}
+	paths := opts.Paths
+
+	pageOpts := &PageOpts{AuthorName: opts.AuthorName, AuthorUrl: opts.AuthorUrl}
+	pages := make([]Page, len(parts))
+	publish := func(i int) error {
+		var prev, next string
+		if i > 0 {
+			prev = pages[i-1].Path
+		}
+		if i+1 < len(parts) {
+			next = pages[i+1].Path
+			if next == "" && i+1 < len(paths) {
+				next = paths[i+1]
+			}
+		}
+		partContent := append(parts[i][:len(parts[i]):len(parts[i])], opts.navigation(prev, next)...)
+
+		var (
+			page *Page
+			err  error
+		)
+		if pages[i].Path != "" {
+			page, err = c.EditPageNodesCtx(ctx, accessToken, pages[i].Path, titles[i], partContent, pageOpts)
+		} else if i < len(paths) && paths[i] != "" {
+			page, err = c.EditPageNodesCtx(ctx, accessToken, paths[i], titles[i], partContent, pageOpts)
+		} else {
+			page, err = c.CreatePageNodesCtx(ctx, accessToken, titles[i], partContent, pageOpts)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to publish part %d of %d: %w", i+1, len(parts), err)
+		}
+		pages[i] = *page
+		return nil
+	}
+
+	for i := range parts {
+		if err := publish(i); err != nil {
+			return pages[:i], err
+		}
+	}
+	// Pages created before the following part existed lack their "Next part" link.
+	for i := 0; i+1 < len(parts); i++ {
+		if i+1 >= len(paths) || paths[i+1] == "" {
+			if err := publish(i); err != nil {
+				return pages, err
+			}
+		}
+	}
+	return pages, nil
+}
+
+func (o *SeriesOpts) partTitle(title string, i int) string {
+	if i == 0 {
+		return title
+	}
+	format := o.PartTitleFormat
+	if format == "" {
+		format = "%s (part %d)"
+	}
+	return fmt.Sprintf(format, title, i+1)
+}
+
+// navigation returns the nodes linking a part to the previous and next ones.
+func (o *SeriesOpts) navigation(prev, next string) []Node {
+	var links []Node
+	if prev != "" {
+		text := o.PreviousPartText
+		if text == "" {
+			text = "Previous part"
+		}
+		links = append(links, &NodeElement{Tag: "a", Attrs: map[string]string{"href": pageURL(prev)}, Children: []Node{TextNode(text)}})
+	}
+	if next != "" {
+		text := o.NextPartText
+		if text == "" {
+			text = "Next part"
+		}
+		if len(links) > 0 {
+			links = append(links, TextNode(" | "))
+		}
+		links = append(links, &NodeElement{Tag: "a", Attrs: map[string]string{"href": pageURL(next)}, Children: []Node{TextNode(text)}})
+	}
+	if links == nil {
+		return nil
+	}
+	return []Node{&NodeElement{Tag: "p", Children: links}}
+}
+
+func pageURL(path string) string {
+	return "https://telegra.ph/" + strings.TrimPrefix(path, "/")
+}
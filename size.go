@@ -0,0 +1,80 @@
+package telegraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// MaxContentSize is the maximum size in bytes of the JSON encoded content of a page accepted by Telegraph.
+const MaxContentSize = 64 * 1024
+
+// ContentTooBigError is returned by CreatePage and EditPage, before anything is sent, when the encoded content exceeds
+// MaxContentSize, and by SplitContent when a single top-level node exceeds the given limit. It matches
+// ErrContentTooBig with errors.Is.
+type ContentTooBigError struct {
+	// Size is the size in bytes of the encoded content.
+	Size int
+	// Limit is the size in bytes that was exceeded: MaxContentSize, or the limit given to SplitContent.
+	Limit int
+	// Node is the index of the first top-level node that makes the content cross Limit.
+	Node int
+}
+
+func (e *ContentTooBigError) Error() string {
+	return fmt.Sprintf("content is %d bytes, %d bytes over the limit of %d bytes, crossed at top-level node %d",
+		e.Size, e.Size-e.Limit, e.Limit, e.Node)
+}
+
+// Is makes ContentTooBigError match ErrContentTooBig.
+func (e *ContentTooBigError) Is(target error) bool {
+	return target == ErrContentTooBig
+}
+
+// ContentSize returns the exact size in bytes of nodes once encoded to be sent to Telegraph.
+func ContentSize(nodes []Node) (int, error) {
+	b, err := encodeNodes(nodes)
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// encodeNodes encodes nodes the way they are sent to Telegraph. HTML characters are not escaped, which keeps the
+// content as small as possible.
+func encodeNodes(nodes interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(nodes); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// encodeContent encodes nodes and checks the result against MaxContentSize.
+func encodeContent(nodes []Node) ([]byte, error) {
+	b, err := encodeNodes(nodes)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) <= MaxContentSize {
+		return b, nil
+	}
+	// Find the top-level node that crosses the limit, accounting for the enclosing brackets and separating commas.
+	size := 2
+	for i, n := range nodes {
+		nb, err := encodeNodes(n)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			size++
+		}
+		size += len(nb)
+		if size > MaxContentSize {
+			return nil, &ContentTooBigError{Size: len(b), Limit: MaxContentSize, Node: i}
+		}
+	}
+	return nil, &ContentTooBigError{Size: len(b), Limit: MaxContentSize, Node: len(nodes) - 1}
+}
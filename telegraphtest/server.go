@@ -0,0 +1,669 @@
+// Package telegraphtest provides an in-memory implementation of the Telegraph API, to test code using the telegraph
+// package without network access, e.g.
+//
+//	srv := telegraphtest.NewServer()
+//	defer srv.Close()
+//	client := telegraph.GetTelegraphClient(srv.ClientOpt())
+//
+// The server implements all the API methods and the upload endpoint, with the validation and errors of Telegraph.
+package telegraphtest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/celestix/telegraph-go/v2"
+)
+
+// MaxFileSize is the size limit of uploaded files.
+const MaxFileSize = telegraph.MaxUploadSize
+
+// uploadTypes are the content types accepted by the upload endpoint, with the extension of the stored files.
+var uploadTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"video/mp4":  ".mp4",
+}
+
+// Server is an in-memory Telegraph API server, listening on a system-chosen port on the local loopback interface.
+type Server struct {
+	// URL is the base URL of the server, of the form http://ipaddr:port with no trailing slash.
+	URL string
+	// ApiUrl is the URL of the API methods, to be used as ClientOpt.ApiUrl.
+	ApiUrl string
+	// UploadUrl is the URL of the upload endpoint, to be used as ClientOpt.UploadUrl.
+	UploadUrl string
+	// Now returns the current time, used to generate page paths and record views. Defaults to time.Now.
+	Now func() time.Time
+
+	srv *httptest.Server
+
+	mu sync.Mutex
+	// accounts are indexed by access token.
+	accounts   map[string]*account
+	pages      map[string]*page
+	files      map[string][]byte
+	floodWaits map[string][]int
+}
+
+type account struct {
+	shortName, authorName, authorUrl string
+	// pages are the paths of the pages created by the account, oldest first.
+	pages []string
+}
+
+type page struct {
+	telegraph.Page
+	owner *account
+	views []time.Time
+}
+
+// NewServer starts and returns a new Server. The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		Now:        time.Now,
+		accounts:   map[string]*account{},
+		pages:      map[string]*page{},
+		files:      map[string][]byte{},
+		floodWaits: map[string][]int{},
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	s.ApiUrl = s.URL + "/"
+	s.UploadUrl = s.URL + "/upload"
+	return s
+}
+
+// Close shuts down the server and blocks until all outstanding requests on this server have completed.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// ClientOpt returns the options of a TelegraphClient sending all its requests to the server.
+func (s *Server) ClientOpt() *telegraph.ClientOpt {
+	return &telegraph.ClientOpt{
+		ApiUrl:     s.ApiUrl,
+		UploadUrl:  s.UploadUrl,
+		HttpClient: s.srv.Client(),
+	}
+}
+
+// Client returns a TelegraphClient sending all its requests to the server.
+func (s *Server) Client() *telegraph.TelegraphClient {
+	return telegraph.GetTelegraphClient(s.ClientOpt())
+}
+
+// FloodWait makes the next call to method ("upload" for the upload endpoint) fail with a FLOOD_WAIT_X error, X
+// being seconds. Successive calls queue several failures.
+func (s *Server) FloodWait(method string, seconds int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.floodWaits[method] = append(s.floodWaits[method], seconds)
+}
+
+// View records a view of the page at path, at the current time. It reports whether the page exists.
+func (s *Server) View(path string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.pages[path]
+	if ok {
+		p.views = append(p.views, s.Now())
+		p.Views++
+	}
+	return ok
+}
+
+// Page returns a copy of the page at path, with its content.
+func (s *Server) Page(path string) (*telegraph.Page, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.pages[path]
+	if !ok {
+		return nil, false
+	}
+	page := p.Page
+	return &page, true
+}
+
+// File returns the content of an uploaded file, src being the path returned by the upload endpoint.
+func (s *Server) File(src string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.files[src]
+	return b, ok
+}
+
+// apiError is an error answered to an API call.
+type apiError string
+
+func (e apiError) Error() string {
+	return string(e)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/file/") {
+		s.serveFile(w, r)
+		return
+	}
+	if r.URL.Path == "/upload" {
+		s.serveUpload(w, r)
+		return
+	}
+
+	// Methods may be called as /method or /method/path.
+	method, path := strings.TrimPrefix(r.URL.Path, "/"), ""
+	if i := strings.IndexByte(method, '/'); i >= 0 {
+		method, path = method[:i], method[i+1:]
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	params, err := url.ParseQuery(string(body))
+	if err != nil {
+		writeResponse(w, nil, apiError("PARAMS_INVALID"))
+		return
+	}
+	for k, v := range r.URL.Query() {
+		if _, ok := params[k]; !ok {
+			params[k] = v
+		}
+	}
+	if path != "" && params.Get("path") == "" {
+		params.Set("path", path)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.floodWait(method); err != nil {
+		writeResponse(w, nil, err)
+		return
+	}
+	var result interface{}
+	switch method {
+	case "createAccount":
+		result, err = s.createAccount(params)
+	case "editAccountInfo":
+		result, err = s.editAccountInfo(params)
+	case "getAccountInfo":
+		result, err = s.getAccountInfo(params)
+	case "revokeAccessToken":
+		result, err = s.revokeAccessToken(params)
+	case "createPage":
+		result, err = s.createPage(params)
+	case "editPage":
+		result, err = s.editPage(params)
+	case "getPage":
+		result, err = s.getPage(params)
+	case "getPageList":
+		result, err = s.getPageList(params)
+	case "getViews":
+		result, err = s.getViews(params)
+	default:
+		err = apiError("METHOD_NOT_FOUND")
+	}
+	writeResponse(w, result, err)
+}
+
+func writeResponse(w http.ResponseWriter, result interface{}, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		_ = json.NewEncoder(w).Encode(telegraph.Body{Error: err.Error()})
+		return
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(telegraph.Body{Ok: true, Result: b})
+}
+
+// floodWait returns the FLOOD_WAIT_X error queued for method, if any.
+func (s *Server) floodWait(method string) error {
+	waits := s.floodWaits[method]
+	if len(waits) == 0 {
+		return nil
+	}
+	s.floodWaits[method] = waits[1:]
+	return apiError("FLOOD_WAIT_" + strconv.Itoa(waits[0]))
+}
+
+func (s *Server) account(params url.Values) (*account, error) {
+	a, ok := s.accounts[params.Get("access_token")]
+	if !ok {
+		return nil, apiError("ACCESS_TOKEN_INVALID")
+	}
+	return a, nil
+}
+
+// validateAuthor checks the author_name and author_url parameters.
+func validateAuthor(params url.Values) error {
+	if utf8.RuneCountInString(params.Get("author_name")) > 128 {
+		return apiError("AUTHOR_NAME_TOO_LONG")
+	}
+	if u := params.Get("author_url"); u != "" {
+		if len(u) > 512 {
+			return apiError("AUTHOR_URL_TOO_LONG")
+		}
+		if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return apiError("AUTHOR_URL_INVALID")
+		}
+	}
+	return nil
+}
+
+func validateShortName(shortName string) error {
+	switch n := utf8.RuneCountInString(shortName); {
+	case n == 0:
+		return apiError("SHORT_NAME_REQUIRED")
+	case n > 32:
+		return apiError("SHORT_NAME_TOO_LONG")
+	}
+	return nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// newToken registers a under a new access token and returns the token.
+func (s *Server) newToken(a *account) string {
+	token := randomHex(30)
+	s.accounts[token] = a
+	return token
+}
+
+// authUrl returns a new URL to authorize a browser, which is never checked by the server.
+func authUrl() string {
+	return "https://edit.telegra.ph/auth/" + randomHex(20)
+}
+
+func (a *account) info() *telegraph.Account {
+	return &telegraph.Account{ShortName: a.shortName, AuthorName: a.authorName, AuthorUrl: a.authorUrl}
+}
+
+func (s *Server) createAccount(params url.Values) (interface{}, error) {
+	if err := validateShortName(params.Get("short_name")); err != nil {
+		return nil, err
+	}
+	if err := validateAuthor(params); err != nil {
+		return nil, err
+	}
+	a := &account{shortName: params.Get("short_name"), authorName: params.Get("author_name"), authorUrl: params.Get("author_url")}
+	info := a.info()
+	info.AccessToken, info.AuthUrl = s.newToken(a), authUrl()
+	return info, nil
+}
+
+func (s *Server) editAccountInfo(params url.Values) (interface{}, error) {
+	a, err := s.account(params)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := params["short_name"]; ok {
+		if err := validateShortName(params.Get("short_name")); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateAuthor(params); err != nil {
+		return nil, err
+	}
+	if _, ok := params["short_name"]; ok {
+		a.shortName = params.Get("short_name")
+	}
+	if _, ok := params["author_name"]; ok {
+		a.authorName = params.Get("author_name")
+	}
+	if _, ok := params["author_url"]; ok {
+		a.authorUrl = params.Get("author_url")
+	}
+	return a.info(), nil
+}
+
+func (s *Server) getAccountInfo(params url.Values) (interface{}, error) {
+	a, err := s.account(params)
+	if err != nil {
+		return nil, err
+	}
+	fields := []string{"short_name", "author_name", "author_url"}
+	if f := params.Get("fields"); f != "" {
+		if json.Unmarshal([]byte(f), &fields) != nil {
+			return nil, apiError("FIELDS_FORMAT_INVALID")
+		}
+	}
+	info := map[string]interface{}{}
+	for _, f := range fields {
+		switch f {
+		case "short_name":
+			info[f] = a.shortName
+		case "author_name":
+			info[f] = a.authorName
+		case "author_url":
+			info[f] = a.authorUrl
+		case "auth_url":
+			info[f] = authUrl()
+		case "page_count":
+			info[f] = len(a.pages)
+		default:
+			return nil, apiError("FIELDS_FORMAT_INVALID")
+		}
+	}
+	return info, nil
+}
+
+func (s *Server) revokeAccessToken(params url.Values) (interface{}, error) {
+	a, err := s.account(params)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.accounts, params.Get("access_token"))
+	info := a.info()
+	info.AccessToken, info.AuthUrl = s.newToken(a), authUrl()
+	return info, nil
+}
+
+// pageParams validates the parameters of createPage and editPage and returns the decoded content.
+func pageParams(params url.Values) ([]telegraph.Node, error) {
+	switch n := utf8.RuneCountInString(params.Get("title")); {
+	case n == 0:
+		return nil, apiError("TITLE_REQUIRED")
+	case n > 256:
+		return nil, apiError("TITLE_TOO_LONG")
+	}
+	if err := validateAuthor(params); err != nil {
+		return nil, err
+	}
+	content := params.Get("content")
+	if content == "" {
+		return nil, apiError("CONTENT_REQUIRED")
+	}
+	if len(content) > telegraph.MaxContentSize {
+		return nil, apiError("CONTENT_TOO_BIG")
+	}
+	nodes, err := telegraph.DecodeNodes([]byte(content))
+	if err != nil {
+		return nil, apiError("CONTENT_FORMAT_INVALID")
+	}
+	if strings.TrimSpace(telegraph.PlainText(nodes)) == "" {
+		return nil, apiError("CONTENT_TEXT_REQUIRED")
+	}
+	return nodes, nil
+}
+
+// description returns the first 150 characters of the text of content.
+func description(content []telegraph.Node) string {
+	text := strings.Join(strings.Fields(telegraph.PlainText(content)), " ")
+	if utf8.RuneCountInString(text) > 150 {
+		text = strings.TrimSpace(string([]rune(text)[:150])) + "…"
+	}
+	return text
+}
+
+// newPath returns a free path for a page with the given title, in the format Title-12-31.
+func (s *Server) newPath(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range title {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			dash = true
+			continue
+		}
+		if dash && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		dash = false
+		b.WriteRune(r)
+	}
+	base := b.String()
+	if base == "" {
+		base = "Untitled"
+	}
+	base += s.Now().Format("-01-02")
+	path := base
+	for i := 2; s.pages[path] != nil; i++ {
+		path = base + "-" + strconv.Itoa(i)
+	}
+	return path
+}
+
+// result returns a copy of p, with its content if return_content is set.
+func (p *page) result(params url.Values) *telegraph.Page {
+	page := p.Page
+	if params.Get("return_content") != "true" {
+		page.Content = nil
+	}
+	return &page
+}
+
+func (s *Server) createPage(params url.Values) (interface{}, error) {
+	a, err := s.account(params)
+	if err != nil {
+		return nil, err
+	}
+	content, err := pageParams(params)
+	if err != nil {
+		return nil, err
+	}
+	path := s.newPath(params.Get("title"))
+	p := &page{owner: a}
+	p.Path = path
+	p.Url = "https://telegra.ph/" + path
+	s.updatePage(p, params, content)
+	s.pages[path] = p
+	a.pages = append(a.pages, path)
+	return p.result(params), nil
+}
+
+func (s *Server) editPage(params url.Values) (interface{}, error) {
+	a, err := s.account(params)
+	if err != nil {
+		return nil, err
+	}
+	path := params.Get("path")
+	if path == "" {
+		return nil, apiError("PATH_REQUIRED")
+	}
+	p, ok := s.pages[path]
+	if !ok {
+		return nil, apiError("PAGE_NOT_FOUND")
+	}
+	if p.owner != a {
+		return nil, apiError("PAGE_ACCESS_DENIED")
+	}
+	content, err := pageParams(params)
+	if err != nil {
+		return nil, err
+	}
+	s.updatePage(p, params, content)
+	return p.result(params), nil
+}
+
+// updatePage sets the title, author and content of p, the author defaulting to the one of its account.
+func (s *Server) updatePage(p *page, params url.Values, content []telegraph.Node) {
+	p.Title = params.Get("title")
+	p.AuthorName, p.AuthorUrl = params.Get("author_name"), params.Get("author_url")
+	if p.AuthorName == "" {
+		p.AuthorName = p.owner.authorName
+	}
+	if p.AuthorUrl == "" {
+		p.AuthorUrl = p.owner.authorUrl
+	}
+	p.Content = content
+	p.Description = description(content)
+	p.ImageUrl = ""
+	if img, _ := telegraph.QueryFirst(content, "img[src]"); img != nil {
+		p.ImageUrl = img.Attrs["src"]
+	}
+}
+
+func (s *Server) getPage(params url.Values) (interface{}, error) {
+	path := params.Get("path")
+	if path == "" {
+		return nil, apiError("PATH_REQUIRED")
+	}
+	p, ok := s.pages[path]
+	if !ok {
+		return nil, apiError("PAGE_NOT_FOUND")
+	}
+	return p.result(params), nil
+}
+
+func (s *Server) getPageList(params url.Values) (interface{}, error) {
+	a, err := s.account(params)
+	if err != nil {
+		return nil, err
+	}
+	offset, limit := 0, 50
+	if v := params.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return nil, apiError("OFFSET_INVALID")
+		}
+	}
+	if v := params.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 0 || limit > 200 {
+			return nil, apiError("LIMIT_INVALID")
+		}
+	}
+	list := telegraph.PageList{TotalCount: int64(len(a.pages)), Pages: []telegraph.Page{}}
+	for i := len(a.pages) - 1 - offset; i >= 0 && len(list.Pages) < limit; i-- {
+		page := s.pages[a.pages[i]].Page
+		page.Content = nil
+		page.CanEdit = true
+		list.Pages = append(list.Pages, page)
+	}
+	return list, nil
+}
+
+func (s *Server) getViews(params url.Values) (interface{}, error) {
+	path := params.Get("path")
+	if path == "" {
+		return nil, apiError("PATH_REQUIRED")
+	}
+	p, ok := s.pages[path]
+	if !ok {
+		return nil, apiError("PAGE_NOT_FOUND")
+	}
+	// Zero values are considered as not passed, like the telegraph package sends them.
+	filter := make([]int, 4)
+	for i, name := range []string{"year", "month", "day", "hour"} {
+		if v := params.Get(name); v != "" && v != "0" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, apiError(strings.ToUpper(name) + "_INVALID")
+			}
+			if i > 0 && filter[i-1] == 0 {
+				return nil, apiError(strings.ToUpper([]string{"year", "month", "day"}[i-1]) + "_REQUIRED")
+			}
+			filter[i] = n
+		}
+	}
+	var views int64
+	for _, t := range p.views {
+		if (filter[0] == 0 || t.Year() == filter[0]) && (filter[1] == 0 || int(t.Month()) == filter[1]) &&
+			(filter[2] == 0 || t.Day() == filter[2]) && (filter[3] == 0 || t.Hour() == filter[3]) {
+			views++
+		}
+	}
+	return telegraph.PageViews{Views: views}, nil
+}
+
+// serveUpload implements the upload endpoint, which answers an array of {"src": path} objects, one per file of the
+// multipart request, or an {"error": description} object.
+func (s *Server) serveUpload(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	srcs, err := s.upload(r)
+	if err != nil {
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	uploads := make([]telegraph.Upload, len(srcs))
+	for i, src := range srcs {
+		uploads[i].Path = src
+	}
+	_ = json.NewEncoder(w).Encode(uploads)
+}
+
+func (s *Server) upload(r *http.Request) ([]string, error) {
+	s.mu.Lock()
+	err := s.floodWait("upload")
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || params["boundary"] == "" {
+		return nil, apiError("Bad request")
+	}
+	var files [][]byte
+	reader := multipart.NewReader(r.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, apiError("Bad request")
+		}
+		if part.FileName() == "" {
+			continue
+		}
+		b, err := io.ReadAll(io.LimitReader(part, MaxFileSize+1))
+		if err != nil {
+			return nil, apiError("Bad request")
+		}
+		if len(b) > MaxFileSize {
+			return nil, apiError("File too big")
+		}
+		files = append(files, b)
+	}
+	if len(files) == 0 {
+		return nil, apiError("No files passed")
+	}
+
+	srcs := make([]string, len(files))
+	for i, b := range files {
+		ext, ok := uploadTypes[http.DetectContentType(b)]
+		if !ok {
+			return nil, apiError("File type invalid")
+		}
+		srcs[i] = "/file/" + randomHex(10) + ext
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, b := range files {
+		s.files[srcs[i]] = b
+	}
+	return srcs, nil
+}
+
+// serveFile serves the uploaded files.
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request) {
+	b, ok := s.File(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeContent(w, r, r.URL.Path, time.Time{}, bytes.NewReader(b))
+}
@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/celestix/telegraph-go/v2"
+)
+
+func TestBuilder(t *testing.T) {
+	content := telegraph.Nodes(
+		telegraph.H3(telegraph.Text("Title")),
+		telegraph.P(telegraph.Text("Hello "), telegraph.B("bold"), telegraph.Text(", "),
+			telegraph.A("https://telegra.ph/", "link", telegraph.I(" here")), telegraph.Br(),
+			telegraph.A("javascript:alert(1)", ""), telegraph.S("", telegraph.U("u"))),
+		telegraph.Figure(telegraph.Img("/file/a.jpg"), telegraph.Figcaption(telegraph.Text("Caption"))),
+		telegraph.Ul(telegraph.Li(telegraph.Text("one")), telegraph.Li(telegraph.I("two"), telegraph.Ol(telegraph.Li()))),
+		telegraph.Pre(telegraph.Code("x := 1")),
+		telegraph.Blockquote(telegraph.Text("quote")),
+		telegraph.Aside(telegraph.Text("aside")),
+		telegraph.Figure(telegraph.Iframe("/embed/youtube?url=https%3A%2F%2Fyoutu.be%2Fx")),
+		telegraph.Video("/file/b.mp4"),
+		telegraph.BlockElement{},
+	)
+
+	expected := `<h3>Title</h3><p>Hello <b>bold</b>, <a href="https://telegra.ph/">link<i> here</i></a><br><a></a><s><u>u</u></s></p>` +
+		`<figure><img src="/file/a.jpg"><figcaption>Caption</figcaption></figure><ul><li>one</li><li><i>two</i><ol><li></li></ol></li></ul>` +
+		`<pre><code>x := 1</code></pre><blockquote>quote</blockquote><aside>aside</aside>` +
+		`<figure><iframe src="/embed/youtube?url=https%3A%2F%2Fyoutu.be%2Fx"></iframe></figure><video src="/file/b.mp4"></video>`
+	if html := telegraph.RenderHTML(content); html != expected {
+		t.Errorf("builder content rendered as %s, expected %s", html, expected)
+	}
+
+	// The built content is valid, so ContentFormat leaves it unchanged.
+	again, err := telegraph.ContentFormat(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !telegraph.NodesEqual(content, again) {
+		got, _ := json.Marshal(again)
+		want, _ := json.Marshal(content)
+		t.Errorf("ContentFormat changed built content\n got: %s\nwant: %s", got, want)
+	}
+}
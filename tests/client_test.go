@@ -0,0 +1,266 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/celestix/telegraph-go/v2"
+)
+
+func TestContextCancel(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	client := telegraph.GetTelegraphClient(&telegraph.ClientOpt{ApiUrl: srv.URL + "/"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetPageCtx(ctx, "Sample-01-01", false)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetPageCtx returned %v, expected context.DeadlineExceeded", err)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/getPage":
+			_, _ = w.Write([]byte(`{"ok":false,"error":"PAGE_NOT_FOUND"}`))
+		case "/getViews":
+			_, _ = w.Write([]byte(`{"ok":false,"error":"FLOOD_WAIT_7"}`))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	client := telegraph.GetTelegraphClient(&telegraph.ClientOpt{ApiUrl: srv.URL + "/"})
+
+	_, err := client.GetPage("Sample-01-01", false)
+	if !errors.Is(err, telegraph.ErrPageNotFound) {
+		t.Errorf("GetPage returned %v, expected ErrPageNotFound", err)
+	}
+
+	_, err = client.GetViews("Sample-01-01", nil)
+	var apiErr *telegraph.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "FLOOD_WAIT" || apiErr.Argument != 7 || apiErr.Method != "getViews" {
+		t.Errorf("GetViews returned %#v, expected FLOOD_WAIT_7 APIError", err)
+	}
+
+	_, err = client.GetAccountInfo("token")
+	var httpErr *telegraph.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("GetAccountInfo returned %v, expected HTTPError with status 502", err)
+	}
+}
+
+func TestRetryFloodWait(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			_, _ = w.Write([]byte(`{"ok":false,"error":"FLOOD_WAIT_0"}`))
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"views":42}}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := telegraph.GetTelegraphClient(&telegraph.ClientOpt{
+		ApiUrl: srv.URL + "/",
+		RetryPolicy: &telegraph.RetryPolicy{
+			MaxAttempts: 3,
+			Backoff:     time.Millisecond,
+		},
+	})
+
+	views, err := client.GetViews("Sample-01-01", nil)
+	if err != nil {
+		t.Fatal("GetViews failed after retries:", err)
+	}
+	if views.Views != 42 || calls != 3 {
+		t.Errorf("GetViews returned %d views after %d calls, expected 42 views after 3 calls", views.Views, calls)
+	}
+}
+
+func TestRetryFloodWaitCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":false,"error":"FLOOD_WAIT_2"}`))
+	}))
+	defer srv.Close()
+
+	client := telegraph.GetTelegraphClient(&telegraph.ClientOpt{
+		ApiUrl:      srv.URL + "/",
+		RetryPolicy: &telegraph.RetryPolicy{},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.GetViewsCtx(ctx, "Sample-01-01", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetViewsCtx returned %v when cancelled during a flood wait, expected context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetViewsCtx returned after %s, expected it to stop waiting at the deadline", elapsed)
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	policy := &telegraph.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}
+	client := telegraph.GetTelegraphClient(&telegraph.ClientOpt{ApiUrl: srv.URL + "/", RetryPolicy: policy})
+
+	// The page may have been created, so a retry could publish it twice.
+	if _, err := client.CreatePage("token", "Sample", "<p>Hello</p>", nil); err == nil || calls != 1 {
+		t.Errorf("CreatePage returned %v after %d calls, expected an error after 1 call", err, calls)
+	}
+
+	calls = 0
+	policy.RetryNonIdempotent = true
+	if _, err := client.CreatePage("token", "Sample", "<p>Hello</p>", nil); err == nil || calls != 3 {
+		t.Errorf("CreatePage returned %v after %d calls with RetryNonIdempotent, expected an error after 3 calls", err, calls)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"views":1}}`))
+	}))
+	defer srv.Close()
+
+	client := telegraph.GetTelegraphClient(&telegraph.ClientOpt{
+		ApiUrl: srv.URL + "/",
+		RateLimiter: telegraph.NewRateLimiter(telegraph.RateLimiterOpts{
+			PerMethod: map[string]telegraph.Rate{"getViews": {Limit: 20, Burst: 1}},
+		}),
+	})
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetViews("Sample-01-01", nil); err != nil {
+				t.Error("GetViews failed:", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 190*time.Millisecond {
+		t.Errorf("5 requests at 20/s took %s, expected at least 200ms", elapsed)
+	}
+}
+
+func TestCreatePageNodes(t *testing.T) {
+	var content string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		u, _ := url.ParseQuery(string(b))
+		content = u.Get("content")
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"path":"Sample-01-01","title":"Sample"}}`))
+	}))
+	defer srv.Close()
+
+	client := telegraph.GetTelegraphClient(&telegraph.ClientOpt{ApiUrl: srv.URL + "/"})
+
+	nodes := []telegraph.Node{
+		&telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{telegraph.TextNode("Hello <world>")}},
+	}
+	if _, err := client.CreatePageNodes("token", "Sample", nodes, nil); err != nil {
+		t.Fatal("CreatePageNodes failed:", err)
+	}
+	if expected := `[{"tag":"p","children":["Hello <world>"]}]`; content != expected {
+		t.Errorf("CreatePageNodes sent content %s, expected %s", content, expected)
+	}
+}
+
+func TestContentTooBig(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer srv.Close()
+
+	client := telegraph.GetTelegraphClient(&telegraph.ClientOpt{ApiUrl: srv.URL + "/"})
+
+	var nodes []telegraph.Node
+	for i := 0; i < 3; i++ {
+		nodes = append(nodes, &telegraph.NodeElement{
+			Tag:      "p",
+			Children: []telegraph.Node{telegraph.TextNode(strings.Repeat("a", 30*1024))},
+		})
+	}
+	size, err := telegraph.ContentSize(nodes)
+	if err != nil {
+		t.Fatal("ContentSize failed:", err)
+	}
+
+	_, err = client.CreatePageNodes("token", "Sample", nodes, nil)
+	var tooBig *telegraph.ContentTooBigError
+	if !errors.Is(err, telegraph.ErrContentTooBig) || !errors.As(err, &tooBig) {
+		t.Fatalf("CreatePageNodes returned %v, expected ContentTooBigError", err)
+	}
+	if tooBig.Size != size || tooBig.Node != 2 {
+		t.Errorf("ContentTooBigError is %+v, expected size %d crossed at node 2", tooBig, size)
+	}
+	if calls != 0 {
+		t.Errorf("CreatePageNodes sent %d requests, expected none", calls)
+	}
+}
+
+func TestClientUrls(t *testing.T) {
+	if client := telegraph.GetTelegraphClient(nil); client.ApiUrl != telegraph.DefaultApiUrl || client.UploadUrl != telegraph.DefaultUploadUrl {
+		t.Errorf("GetTelegraphClient(nil) uses %s and %s, expected the default URLs", client.ApiUrl, client.UploadUrl)
+	}
+
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/mirror/upload":
+			_, _ = w.Write([]byte(`[{"src":"/file/a.jpg"}]`))
+		default:
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"views":1}}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := telegraph.GetTelegraphClient(&telegraph.ClientOpt{
+		ApiUrl:    srv.URL + "/mirror/api/",
+		UploadUrl: srv.URL + "/mirror/upload",
+	})
+	if _, err := client.GetViews("Sample-01-01", nil); err != nil {
+		t.Fatal("GetViews failed:", err)
+	}
+	if _, err := client.UploadFileByBytes([]byte("GIF89a")); err != nil {
+		t.Fatal("UploadFileByBytes failed:", err)
+	}
+	if expected := []string{"/mirror/api/getViews", "/mirror/upload"}; strings.Join(paths, " ") != strings.Join(expected, " ") {
+		t.Errorf("client requested %v, expected %v", paths, expected)
+	}
+}
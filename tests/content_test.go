@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/celestix/telegraph-go/v2"
+)
+
+// TestContentFormatGolden converts every data/content/*.html file and compares the result with the
+// Telegraph content format stored next to it in the matching .json file.
+func TestContentFormatGolden(t *testing.T) {
+	testGolden(t, "data/content/*.html", func(input []byte) ([]telegraph.Node, error) {
+		return telegraph.ContentFormat(input)
+	})
+}
+
+// TestMarkdownFormatGolden does the same for every data/markdown/*.md file.
+func TestMarkdownFormatGolden(t *testing.T) {
+	testGolden(t, "data/markdown/*.md", func(input []byte) ([]telegraph.Node, error) {
+		return telegraph.MarkdownFormat(input)
+	})
+}
+
+func testGolden(t *testing.T, pattern string, convert func([]byte) ([]telegraph.Node, error)) {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		input, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		golden, err := os.ReadFile(strings.TrimSuffix(file, filepath.Ext(file)) + ".json")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		nodes, err := convert(input)
+		if err != nil {
+			t.Errorf("Conversion failed for %s: %v", file, err)
+			continue
+		}
+		output, err := json.Marshal(nodes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(output, bytes.TrimSpace(golden)) {
+			t.Errorf("Conversion mismatch for %s:\n got: %s\nwant: %s", file, output, bytes.TrimSpace(golden))
+		}
+	}
+}
+
+func TestContentFormatTagRules(t *testing.T) {
+	nodes, err := telegraph.ContentFormatWithOpts(`<p>Video <iframe src="https://example.com"></iframe></p><custom>kept</custom>`, &telegraph.ContentFormatOpts{
+		Tags:       map[string]telegraph.TagRule{"iframe": {Drop: true}},
+		UnknownTag: telegraph.TagRule{Tag: "code"},
+	})
+	if err != nil {
+		t.Fatal("ContentFormatWithOpts failed:", err)
+	}
+	output, _ := json.Marshal(nodes)
+	if expected := `[{"tag":"p","children":["Video"]},{"tag":"code","children":["kept"]}]`; string(output) != expected {
+		t.Errorf("ContentFormatWithOpts returned %s, expected %s", output, expected)
+	}
+}
+
+func TestContentFormatAllowSchemes(t *testing.T) {
+	nodes, err := telegraph.ContentFormatWithOpts(`<img src="data:image/png;base64,AAAA">`, &telegraph.ContentFormatOpts{
+		AllowSchemes: []string{"data"},
+	})
+	if err != nil {
+		t.Fatal("ContentFormatWithOpts failed:", err)
+	}
+	output, _ := json.Marshal(nodes)
+	if expected := `[{"tag":"img","attrs":{"src":"data:image/png;base64,AAAA"}}]`; string(output) != expected {
+		t.Errorf("ContentFormatWithOpts returned %s, expected %s", output, expected)
+	}
+}
+
+func TestContentFormatKeepWhitespace(t *testing.T) {
+	nodes, err := telegraph.ContentFormatWithOpts("<p> a  <!-- note -->b </p>\n", &telegraph.ContentFormatOpts{
+		KeepWhitespace: true,
+	})
+	if err != nil {
+		t.Fatal("ContentFormatWithOpts failed:", err)
+	}
+	output, _ := json.Marshal(nodes)
+	if expected := `[{"tag":"p","children":[" a  b "]},"\n"]`; string(output) != expected {
+		t.Errorf("ContentFormatWithOpts returned %s, expected %s", output, expected)
+	}
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/celestix/telegraph-go/v2"
+)
+
+func TestDiffNodes(t *testing.T) {
+	old, _ := telegraph.ContentFormat(`<h3>Title</h3><p>Hello <a href="https://a.example/">link</a></p><p>Removed</p><p>Kept</p>`)
+	new, _ := telegraph.ContentFormat(`<h4>Title</h4><p>Hi <a href="https://b.example/">link</a></p><p>Kept</p><hr>`)
+
+	if telegraph.NodesEqual(old, new) || !telegraph.NodesEqual(old, old) {
+		t.Error("NodesEqual returned a wrong result")
+	}
+
+	var changes []string
+	for _, c := range telegraph.DiffNodes(old, new) {
+		changes = append(changes, c.String())
+	}
+	expected := []string{
+		`modified at /0: <h3> -> <h4>`,
+		`text changed at /1/0: "Hello " -> "Hi "`,
+		`attrs changed of <a> at /1/1: href "https://a.example/" -> "https://b.example/"`,
+		`deleted <p> at /2`,
+		`inserted <hr> at /3`,
+	}
+	if strings.Join(changes, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("DiffNodes returned:\n%s\nexpected:\n%s", strings.Join(changes, "\n"), strings.Join(expected, "\n"))
+	}
+
+	if diff := telegraph.UnifiedDiff(old, old); diff != "" {
+		t.Errorf("UnifiedDiff of equal trees returned %q", diff)
+	}
+	expectedDiff := `--- old
++++ new
+@@ -1,10 +1,9 @@
+-<h3>
++<h4>
+   "Title"
+ <p>
+-  "Hello "
+-  <a href="https://a.example/">
++  "Hi "
++  <a href="https://b.example/">
+     "link"
+ <p>
+-  "Removed"
+-<p>
+   "Kept"
++<hr>
+`
+	if diff := telegraph.UnifiedDiff(old, new); diff != expectedDiff {
+		t.Errorf("UnifiedDiff returned:\n%s\nexpected:\n%s", diff, expectedDiff)
+	}
+}
+
+func TestDiffNodesLarge(t *testing.T) {
+	// The common ends of both trees are matched without building a table of 20000² entries.
+	var old, new []telegraph.Node
+	for i := 0; i < 20000; i++ {
+		p := &telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{telegraph.TextNode(fmt.Sprint(i))}}
+		old = append(old, p)
+		if i == 10000 {
+			p = &telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{"changed"}}
+		}
+		new = append(new, p)
+	}
+	changes := telegraph.DiffNodes(old, new)
+	if len(changes) != 1 || changes[0].String() != `text changed at /10000/0: "10000" -> "changed"` {
+		t.Errorf("DiffNodes returned %v, expected a single text change", changes)
+	}
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/celestix/telegraph-go/v2"
+)
+
+func TestRenderMarkdownRoundTrip(t *testing.T) {
+	nodes, err := telegraph.MarkdownFormat("## Title\n\n" +
+		"Some *em*, **strong**, ~~del~~, `code`, <u>underlined *text*</u> and a [link](https://telegra.ph/) with 1. *stars* \\& [brackets]\\\nnext line\n\n" +
+		"![A caption](/file/a086583f5b7b25cd428fb.jpg)\n\n" +
+		"[![Badge](/file/badge.png)](https://telegra.ph/)\n\n" +
+		"### Section\n\n" +
+		"> quote\\\n> second line\n\n" +
+		"- one\n- two\n  - nested\n\n" +
+		"1. first\n2. second\n\n" +
+		"```\nfunc main() {}\n```\n\n" +
+		"<aside>An <b>aside</b></aside>\n\n" +
+		"---\n")
+	if err != nil {
+		t.Fatal("MarkdownFormat failed:", err)
+	}
+	md := telegraph.RenderMarkdown(nodes)
+	again, err := telegraph.MarkdownFormat(md)
+	if err != nil {
+		t.Fatal("MarkdownFormat failed on rendered Markdown:", err)
+	}
+
+	expected, _ := json.Marshal(nodes)
+	output, _ := json.Marshal(again)
+	if string(expected) != string(output) {
+		t.Errorf("RenderMarkdown did not round-trip, rendered:\n%s\n got: %s\nwant: %s", md, output, expected)
+	}
+	if !strings.Contains(string(output), `{"tag":"u","children":["underlined ",{"tag":"em","children":["text"]}]}`) {
+		t.Errorf("MarkdownFormat lost the underline: %s", output)
+	}
+
+	// Images outside of figures are kept.
+	img := []telegraph.Node{&telegraph.NodeElement{Tag: "img", Attrs: map[string]string{"src": "/file/a.jpg"}}}
+	if md := telegraph.RenderMarkdown(img); md != "![](/file/a.jpg)\n" {
+		t.Errorf("RenderMarkdown returned %q for an image, expected %q", md, "![](/file/a.jpg)\n")
+	}
+
+	// Headings cannot span several lines, so their line breaks become spaces.
+	heading := []telegraph.Node{&telegraph.NodeElement{Tag: "h4", Children: []telegraph.Node{
+		telegraph.TextNode("a"), &telegraph.NodeElement{Tag: "br"}, telegraph.TextNode("b"),
+	}}}
+	if md := telegraph.RenderMarkdown(heading); md != "### a b\n" {
+		t.Errorf("RenderMarkdown returned %q for a heading with a line break, expected %q", md, "### a b\n")
+	}
+}
+
+func TestRenderMarkdownEmbeds(t *testing.T) {
+	page := telegraph.Page{Content: []telegraph.Node{
+		&telegraph.NodeElement{Tag: "figure", Children: []telegraph.Node{
+			&telegraph.NodeElement{Tag: "iframe", Attrs: map[string]string{"src": "/embed/youtube?url=https%3A%2F%2Fwww.youtube.com%2Fwatch%3Fv%3Dabc"}},
+			&telegraph.NodeElement{Tag: "figcaption", Children: []telegraph.Node{telegraph.TextNode("A video")}},
+		}},
+		&telegraph.NodeElement{Tag: "video", Attrs: map[string]string{"src": "/file/video.mp4"}},
+	}}
+	expected := "[A video](https://www.youtube.com/watch?v=abc)\n\n[https://telegra.ph/file/video.mp4](https://telegra.ph/file/video.mp4)\n"
+	if md := page.Markdown(); md != expected {
+		t.Errorf("Markdown returned %q, expected %q", md, expected)
+	}
+}
@@ -0,0 +1,30 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/celestix/telegraph-go/v2"
+)
+
+func TestPageContentDecoding(t *testing.T) {
+	var page telegraph.Page
+	err := json.Unmarshal([]byte(`{"path":"Sample-01-01","title":"Sample","content":["intro",{"tag":"p","children":[{"tag":"a","attrs":{"href":"https://telegra.ph/"},"children":["link"]}]}]}`), &page)
+	if err != nil {
+		t.Fatal("Failed to decode page:", err)
+	}
+	if page.Path != "Sample-01-01" || len(page.Content) != 2 {
+		t.Fatalf("Decoded page %+v has unexpected fields", page)
+	}
+	if text, ok := page.Content[0].(telegraph.TextNode); !ok || text != "intro" {
+		t.Errorf("First node is %#v, expected TextNode intro", page.Content[0])
+	}
+	p, ok := page.Content[1].(*telegraph.NodeElement)
+	if !ok || p.Tag != "p" || len(p.Children) != 1 {
+		t.Fatalf("Second node is %#v, expected p NodeElement", page.Content[1])
+	}
+	a, ok := p.Children[0].(*telegraph.NodeElement)
+	if !ok || a.Attrs["href"] != "https://telegra.ph/" || a.Children[0] != telegraph.TextNode("link") {
+		t.Errorf("Nested node is %#v, expected a NodeElement with href", p.Children[0])
+	}
+}
@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/celestix/telegraph-go/v2"
+	"github.com/celestix/telegraph-go/v2/telegraphtest"
+)
+
+func TestPublishPage(t *testing.T) {
+	pages := map[string]*telegraph.Page{}
+	srv := pagesServer(t, pages)
+	defer srv.Close()
+
+	client := telegraph.GetTelegraphClient(&telegraph.ClientOpt{ApiUrl: srv.URL + "/"})
+	opts := &telegraph.PageOpts{AuthorName: "CI"}
+
+	page, action, err := client.PublishPage("token", "Missing-01-01", "Docs", "<p>Hello</p>", opts)
+	if err != nil || action != telegraph.PublishCreated {
+		t.Fatalf("PublishPage returned %v, %v, expected a created page", action, err)
+	}
+	path := page.Path
+
+	// Split text nodes are normalized before comparing.
+	nodes := []telegraph.Node{&telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{"Hel", telegraph.TextNode("lo")}}}
+	if _, action, err = client.PublishPage("token", path, "Docs", nodes, opts); err != nil || action != telegraph.PublishUnchanged {
+		t.Errorf("PublishPage returned %v, %v, expected an unchanged page", action, err)
+	}
+
+	if _, action, err = client.PublishPage("token", path, "Docs", "<p>Hello</p>", &telegraph.PageOpts{AuthorName: "Bot"}); err != nil || action != telegraph.PublishUpdated {
+		t.Errorf("PublishPage returned %v, %v, expected an updated page", action, err)
+	}
+	if len(pages) != 1 || pages[path].AuthorName != "Bot" {
+		t.Errorf("Server has %d pages, expected the page to be updated in place", len(pages))
+	}
+}
+
+func TestPublishPageAccountAuthor(t *testing.T) {
+	srv := telegraphtest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	account, err := client.CreateAccount("Sandbox", &telegraph.CreateAccountOpts{AuthorName: "Anon"})
+	if err != nil {
+		t.Fatal("CreateAccount failed:", err)
+	}
+	page, _, err := client.PublishPage(account.AccessToken, "", "Docs", "<p>Hello</p>", nil)
+	if err != nil {
+		t.Fatal("PublishPage failed:", err)
+	}
+
+	// The author filled in by Telegraph from the account is not a change.
+	if _, action, err := client.PublishPage(account.AccessToken, page.Path, "Docs", "<p>Hello</p>", nil); err != nil || action != telegraph.PublishUnchanged {
+		t.Errorf("PublishPage returned %v, %v, expected an unchanged page", action, err)
+	}
+}
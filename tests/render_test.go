@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/celestix/telegraph-go/v2"
+)
+
+// TestRenderHTMLRoundTrip renders the golden content of data/content back to HTML and checks that ContentFormat
+// converts it to the same nodes.
+func TestRenderHTMLRoundTrip(t *testing.T) {
+	files, err := filepath.Glob("data/content/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		golden, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes, err := telegraph.DecodeNodes(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rendered := telegraph.RenderHTML(nodes)
+		again, err := telegraph.ContentFormat(rendered)
+		if err != nil {
+			t.Fatal(err)
+		}
+		output, _ := json.Marshal(again)
+		if expected := strings.TrimSpace(string(golden)); string(output) != expected {
+			t.Errorf("RenderHTML did not round-trip for %s, rendered:\n%s\n got: %s\nwant: %s", file, rendered, output, expected)
+		}
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	nodes := []telegraph.Node{
+		&telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{
+			telegraph.TextNode(`<script>"&"</script>`),
+			&telegraph.NodeElement{Tag: "br"},
+			&telegraph.NodeElement{Tag: "a", Attrs: map[string]string{"href": `https://telegra.ph/?a="b"`}, Children: []telegraph.Node{telegraph.TextNode("link")}},
+			&telegraph.NodeElement{Tag: "script", Children: []telegraph.Node{telegraph.TextNode("unwrapped")}},
+		}},
+		&telegraph.NodeElement{Tag: "img", Attrs: map[string]string{"src": "javascript:alert(1)", "onerror": "alert(1)"}},
+	}
+	expected := `<p>&lt;script&gt;&#34;&amp;&#34;&lt;/script&gt;<br><a href="https://telegra.ph/?a=&#34;b&#34;">link</a>unwrapped</p><img>`
+	if html := telegraph.RenderHTML(nodes); html != expected {
+		t.Errorf("RenderHTML returned %s, expected %s", html, expected)
+	}
+}
+
+func TestRenderHTMLDocument(t *testing.T) {
+	page := &telegraph.Page{
+		Title:      "Sample <Page>",
+		AuthorName: "User1",
+		AuthorUrl:  "https://t.me/user1",
+		Content:    []telegraph.Node{&telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{telegraph.TextNode("Hello")}}},
+	}
+	doc, err := telegraph.RenderHTMLDocument(page, &telegraph.HTMLDocumentOpts{
+		Date: time.Date(2021, time.August, 13, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal("RenderHTMLDocument failed:", err)
+	}
+	for _, expected := range []string{
+		"<title>Sample &lt;Page&gt;</title>",
+		`<a rel="author" href="https://t.me/user1">User1</a>`,
+		`<time datetime="2021-08-13T00:00:00Z">August 13, 2021</time>`,
+		"<p>Hello</p>",
+	} {
+		if !strings.Contains(doc, expected) {
+			t.Errorf("RenderHTMLDocument output lacks %s:\n%s", expected, doc)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/celestix/telegraph-go/v2"
+	"github.com/celestix/telegraph-go/v2/telegraphtest"
+)
+
+// pagesServer is a minimal in-memory implementation of createPage, editPage and getPage.
+func pagesServer(t *testing.T, pages map[string]*telegraph.Page) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		u, _ := url.ParseQuery(string(b))
+		var result interface{}
+		switch r.URL.Path {
+		case "/createPage", "/editPage":
+			content, err := telegraph.DecodeNodes([]byte(u.Get("content")))
+			if err != nil {
+				t.Error("Server received invalid content:", err)
+			}
+			path := u.Get("path")
+			if path == "" {
+				path = fmt.Sprintf("Page-%d", len(pages)+1)
+			}
+			pages[path] = &telegraph.Page{Path: path, Title: u.Get("title"), AuthorName: u.Get("author_name"), Content: content}
+			result = pages[path]
+		case "/getPage":
+			page, ok := pages[u.Get("path")]
+			if !ok {
+				_, _ = w.Write([]byte(`{"ok":false,"error":"PAGE_NOT_FOUND"}`))
+				return
+			}
+			result = page
+		}
+		res, _ := json.Marshal(result)
+		_, _ = fmt.Fprintf(w, `{"ok":true,"result":%s}`, res)
+	}))
+}
+
+func TestPublishSeries(t *testing.T) {
+	pages := map[string]*telegraph.Page{}
+	srv := pagesServer(t, pages)
+	defer srv.Close()
+
+	client := telegraph.GetTelegraphClient(&telegraph.ClientOpt{ApiUrl: srv.URL + "/"})
+
+	nodes := seriesContent()
+
+	opts := &telegraph.SeriesOpts{}
+	for run := 0; run < 2; run++ {
+		series, err := client.PublishSeries("token", "Long", nodes, opts)
+		if err != nil {
+			t.Fatal("PublishSeries failed:", err)
+		}
+		if len(series) != 3 || len(pages) != 3 {
+			t.Fatalf("PublishSeries returned %d parts with %d pages on the server, expected 3", len(series), len(pages))
+		}
+		if series[1].Title != "Long (part 2)" {
+			t.Errorf("Second part has title %q", series[1].Title)
+		}
+		for i, page := range series {
+			content := pages[page.Path].Content
+			nav := content[len(content)-1].(*telegraph.NodeElement)
+			var links []string
+			for _, n := range nav.Children {
+				if a, ok := n.(*telegraph.NodeElement); ok {
+					links = append(links, a.Attrs["href"])
+				}
+			}
+			var expected []string
+			if i > 0 {
+				expected = append(expected, "https://telegra.ph/"+series[i-1].Path)
+			}
+			if i+1 < len(series) {
+				expected = append(expected, "https://telegra.ph/"+series[i+1].Path)
+			}
+			if strings.Join(links, " ") != strings.Join(expected, " ") {
+				t.Errorf("Part %d links to %v, expected %v", i+1, links, expected)
+			}
+		}
+
+		// Re-publish over the pages of this run.
+		opts.Paths = nil
+		for _, page := range series {
+			opts.Paths = append(opts.Paths, page.Path)
+		}
+	}
+}
+
+func TestPublishSeriesResume(t *testing.T) {
+	srv := telegraphtest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	account, err := client.CreateAccount("Sandbox", nil)
+	if err != nil {
+		t.Fatal("CreateAccount failed:", err)
+	}
+	nodes := seriesContent()
+
+	// The title of the second part is too long for Telegraph.
+	series, err := client.PublishSeries(account.AccessToken, strings.Repeat("a", 250), nodes, nil)
+	if !errors.Is(err, telegraph.ErrTitleTooLong) || len(series) != 1 || series[0].Path == "" {
+		t.Fatalf("PublishSeries returned %v, %v, expected the first part along with ErrTitleTooLong", series, err)
+	}
+
+	opts := &telegraph.SeriesOpts{Paths: []string{series[0].Path}}
+	series, err = client.PublishSeries(account.AccessToken, "Long", nodes, opts)
+	if err != nil || len(series) != 3 || series[0].Path != opts.Paths[0] {
+		t.Fatalf("PublishSeries returned %v, %v, expected 3 parts starting with %s", series, err, opts.Paths[0])
+	}
+	if list, err := account.GetPageList(client, nil); err != nil || list.TotalCount != 3 {
+		t.Errorf("GetPageList returned %+v, %v, expected the 3 parts only", list, err)
+	}
+}
+
+// seriesContent returns content split into 3 parts by PublishSeries.
+func seriesContent() []telegraph.Node {
+	var nodes []telegraph.Node
+	for i := 0; i < 5; i++ {
+		nodes = append(nodes, &telegraph.NodeElement{
+			Tag:      "p",
+			Children: []telegraph.Node{telegraph.TextNode(strings.Repeat("a", 30*1024))},
+		})
+	}
+	return nodes
+}
+
+func TestSplitContent(t *testing.T) {
+	nodes := []telegraph.Node{telegraph.TextNode("aaaa"), telegraph.TextNode("bbbb"), telegraph.TextNode("cccc")}
+	parts, err := telegraph.SplitContent(nodes, len(`["aaaa","bbbb"]`))
+	if err != nil {
+		t.Fatal("SplitContent failed:", err)
+	}
+	if len(parts) != 2 || len(parts[0]) != 2 || len(parts[1]) != 1 {
+		t.Errorf("SplitContent returned %v, expected 2 parts", parts)
+	}
+
+	// A list too big for a single part is split between its items.
+	li := telegraph.Li(telegraph.Text("item"))
+	list := telegraph.Nodes(telegraph.Ul(li, li, li))
+	limit, _ := telegraph.ContentSize(telegraph.Nodes(telegraph.Ul(li, li)))
+	parts, err = telegraph.SplitContent(list, limit)
+	if err != nil {
+		t.Fatal("SplitContent failed on a list:", err)
+	}
+	expected := [][]telegraph.Node{telegraph.Nodes(telegraph.Ul(li, li)), telegraph.Nodes(telegraph.Ul(li))}
+	if len(parts) != 2 || !telegraph.NodesEqual(parts[0], expected[0]) || !telegraph.NodesEqual(parts[1], expected[1]) {
+		got, _ := json.Marshal(parts)
+		t.Errorf("SplitContent returned %s for a list, expected it split after 2 items", got)
+	}
+
+	_, err = telegraph.SplitContent(nodes, 6)
+	if expected := "content is 8 bytes, 2 bytes over the limit of 6 bytes, crossed at top-level node 0"; err == nil || err.Error() != expected {
+		t.Errorf("SplitContent returned %v, expected %q", err, expected)
+	}
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/celestix/telegraph-go/v2"
+	"github.com/celestix/telegraph-go/v2/telegraphtest"
+)
+
+func TestTelegraphTestServer(t *testing.T) {
+	srv := telegraphtest.NewServer()
+	defer srv.Close()
+	srv.Now = func() time.Time { return time.Date(2024, 12, 31, 10, 0, 0, 0, time.UTC) }
+
+	opt := srv.ClientOpt()
+	opt.RetryPolicy = &telegraph.RetryPolicy{MaxAttempts: 2}
+	client := telegraph.GetTelegraphClient(opt)
+
+	if _, err := client.CreateAccount("", nil); !errors.Is(err, telegraph.ErrShortNameRequired) {
+		t.Errorf("CreateAccount returned %v, expected ErrShortNameRequired", err)
+	}
+	account, err := client.CreateAccount("Sandbox", &telegraph.CreateAccountOpts{AuthorName: "Anonymous"})
+	if err != nil {
+		t.Fatal("CreateAccount failed:", err)
+	}
+
+	content := telegraph.Nodes(telegraph.P(telegraph.Text("Hello, world!")))
+	page, err := account.CreatePageNodes(client, "Sample Page", content, nil)
+	if err != nil {
+		t.Fatal("CreatePageNodes failed:", err)
+	}
+	if page.Path != "Sample-Page-12-31" || page.AuthorName != "Anonymous" || page.Description != "Hello, world!" {
+		t.Errorf("CreatePageNodes returned %+v", page)
+	}
+	if again, _ := account.CreatePageNodes(client, "Sample Page", content, nil); again == nil || again.Path != "Sample-Page-12-31-2" {
+		t.Errorf("CreatePageNodes returned %+v for a duplicate title, expected path Sample-Page-12-31-2", again)
+	}
+
+	srv.FloodWait("getPage", 0)
+	got, err := client.GetPage(page.Path, true)
+	if err != nil {
+		t.Fatal("GetPage failed after a flood wait:", err)
+	}
+	if !telegraph.NodesEqual(got.Content, content) {
+		t.Errorf("GetPage returned content %v, expected %v", got.Content, content)
+	}
+
+	srv.View(page.Path)
+	if views, err := page.GetViews(client, &telegraph.PageViewsOpts{Year: 2024, Month: 12}); err != nil || views.Views != 1 {
+		t.Errorf("GetViews returned %v, %v, expected 1 view", views, err)
+	}
+	if list, err := account.GetPageList(client, nil); err != nil || list.TotalCount != 2 || list.Pages[1].Path != page.Path {
+		t.Errorf("GetPageList returned %+v, %v", list, err)
+	}
+
+	// Bypass the size check of the client.
+	big, _ := json.Marshal(telegraph.Nodes(telegraph.P(telegraph.Text(strings.Repeat("a", telegraph.MaxContentSize)))))
+	if _, err := client.InvokeRequest("createPage", url.Values{
+		"access_token": {account.AccessToken},
+		"title":        {"Big"},
+		"content":      {string(big)},
+	}); !errors.Is(err, telegraph.ErrContentTooBig) {
+		t.Errorf("createPage returned %v, expected ErrContentTooBig", err)
+	}
+
+	other, err := client.CreateAccount("Other", nil)
+	if err != nil {
+		t.Fatal("CreateAccount failed:", err)
+	}
+	if _, err := other.EditPageNodes(client, page.Path, "Stolen", content, nil); !errors.Is(err, telegraph.ErrPageAccessDenied) {
+		t.Errorf("EditPageNodes returned %v, expected ErrPageAccessDenied", err)
+	}
+
+	revoked, err := account.RevokeAccessToken(client)
+	if err != nil {
+		t.Fatal("RevokeAccessToken failed:", err)
+	}
+	if _, err := account.GetInfo(client); !errors.Is(err, telegraph.ErrAccessTokenInvalid) {
+		t.Errorf("GetInfo returned %v with a revoked token, expected ErrAccessTokenInvalid", err)
+	}
+	if info, err := revoked.GetInfo(client); err != nil || info.PageCount != 2 {
+		t.Errorf("GetInfo returned %+v, %v, expected 2 pages", info, err)
+	}
+
+	// Claim a supported type to bypass the checks of the client.
+	_, err = client.UploadReader(strings.NewReader("plain text"), "notes.png", &telegraph.UploadOpts{ContentType: "image/png"})
+	var apiErr *telegraph.APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, telegraph.ErrFileTypeInvalid) {
+		t.Errorf("UploadReader returned %v for a text file, expected File type invalid from the server", err)
+	}
+}
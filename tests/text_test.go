@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/celestix/telegraph-go/v2"
+)
+
+func TestPlainTextAndStats(t *testing.T) {
+	nodes, err := telegraph.ContentFormat(`<h3>Title</h3><p>Hello <b>world</b>,<br>see <a href="https://telegra.ph/">this link</a>.</p>` +
+		`<ul><li>one</li><li>two</li></ul><figure><img src="/file/a.jpg"><figcaption>Caption</figcaption></figure>` +
+		`<figure><iframe src="/embed/youtube?url=x"></iframe></figure><pre>code  block</pre>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := telegraph.Page{Content: nodes}
+
+	expected := "Title\n\nHello world,\nsee this link.\n\none\ntwo\n\nCaption\n\ncode  block"
+	if text := page.PlainText(); text != expected {
+		t.Errorf("PlainText returned %q, expected %q", text, expected)
+	}
+
+	stats := page.Stats()
+	if stats.Words != 11 || stats.Characters != 55 || stats.Images != 1 || stats.Links != 1 || stats.Embeds != 1 {
+		t.Errorf("Stats returned %+v", stats)
+	}
+	if expected := 11*time.Minute/telegraph.WordsPerMinute + 12*time.Second; stats.ReadingTime != expected {
+		t.Errorf("Stats returned reading time %s, expected %s", stats.ReadingTime, expected)
+	}
+}
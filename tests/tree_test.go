@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/celestix/telegraph-go/v2"
+)
+
+const treeHTML = `<h3>First</h3><p>Intro <a href="https://example.com/">out</a></p>` +
+	`<blockquote>Quote <a href="/Page-01-01">in</a> <b><a href="https://telegra.ph/x">deep</a></b></blockquote>` +
+	`<figure><img src="/file/a.jpg"><figcaption>A</figcaption></figure><h3>Second</h3><img src="/file/b.jpg">`
+
+func TestQuery(t *testing.T) {
+	nodes, err := telegraph.ContentFormat(treeHTML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imgs, err := telegraph.Query(nodes, "img[src]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imgs) != 2 || imgs[0].Attrs["src"] != "/file/a.jpg" || imgs[1].Attrs["src"] != "/file/b.jpg" {
+		t.Errorf("Query img[src] returned %v", imgs)
+	}
+
+	links, _ := telegraph.Query(nodes, "blockquote a")
+	if len(links) != 2 {
+		t.Errorf("Query blockquote a returned %d elements, expected 2", len(links))
+	}
+	links, _ = telegraph.Query(nodes, "blockquote > a, p > a[href^=https]")
+	if len(links) != 2 || links[0].Attrs["href"] != "https://example.com/" || links[1].Attrs["href"] != "/Page-01-01" {
+		t.Errorf("Query blockquote > a, p > a[href^=https] returned %v", links)
+	}
+
+	h3, _ := telegraph.QueryFirst(nodes, "h3")
+	if h3 == nil || telegraph.PlainText(h3.Children) != "First" {
+		t.Errorf("QueryFirst h3 returned %v", h3)
+	}
+
+	if _, err := telegraph.Query(nodes, "p > > a"); err == nil {
+		t.Error("Query accepted an invalid selector")
+	}
+
+	// Commas, spaces, ">" and "]" are part of quoted attribute values.
+	quoted, _ := telegraph.ContentFormat(`<p><a href="https://x/a,b">1</a><a href="a b">2</a><a href="a>b]">3</a></p>`)
+	for selector, text := range map[string]string{
+		`a[href="https://x/a,b"]`: "1",
+		`p > a[href='a b']`:       "2",
+		`a[href$="b]"]`:           "3",
+	} {
+		links, err := telegraph.Query(quoted, selector)
+		if err != nil || len(links) != 1 || telegraph.PlainText(links[0].Children) != text {
+			t.Errorf("Query %s returned %v, %v, expected link %s", selector, links, err, text)
+		}
+	}
+}
+
+func TestWalkAndTransform(t *testing.T) {
+	nodes, err := telegraph.ContentFormat(treeHTML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var texts []string
+	telegraph.Walk(nodes, func(n telegraph.Node, parent *telegraph.NodeElement) telegraph.WalkAction {
+		if e, ok := n.(*telegraph.NodeElement); ok && e.Tag == "blockquote" {
+			return telegraph.WalkSkipChildren
+		}
+		if text, ok := n.(telegraph.TextNode); ok {
+			texts = append(texts, string(text))
+		}
+		return telegraph.WalkContinue
+	})
+	if strings.Join(texts, "|") != "First|Intro |out|A|Second" {
+		t.Errorf("Walk visited %v", texts)
+	}
+
+	// Rewrite relative links, unwrap bold text and remove figures.
+	nodes = telegraph.Transform(nodes, func(n telegraph.Node) []telegraph.Node {
+		e, ok := n.(*telegraph.NodeElement)
+		switch {
+		case !ok:
+		case e.Tag == "a" && strings.HasPrefix(e.Attrs["href"], "/"):
+			e.Attrs["href"] = "https://telegra.ph" + e.Attrs["href"]
+		case e.Tag == "b":
+			return e.Children
+		case e.Tag == "figure":
+			return nil
+		}
+		return []telegraph.Node{n}
+	})
+	output, _ := json.Marshal(nodes)
+	expected := `[{"tag":"h3","children":["First"]},{"tag":"p","children":["Intro ",{"tag":"a","attrs":{"href":"https://example.com/"},"children":["out"]}]},` +
+		`{"tag":"blockquote","children":["Quote ",{"tag":"a","attrs":{"href":"https://telegra.ph/Page-01-01"},"children":["in"]}," ",{"tag":"a","attrs":{"href":"https://telegra.ph/x"},"children":["deep"]}]},` +
+		`{"tag":"h3","children":["Second"]},{"tag":"img","attrs":{"src":"/file/b.jpg"}}]`
+	if string(output) != expected {
+		t.Errorf("Transform returned %s, expected %s", output, expected)
+	}
+}
@@ -1,16 +1,29 @@
 package tests
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/celestix/telegraph-go/v2"
+	"github.com/celestix/telegraph-go/v2/telegraphtest"
 )
 
 func TestUploadPhoto01(t *testing.T) {
-	client := telegraph.GetTelegraphClient(nil)
+	srv := telegraphtest.NewServer()
+	defer srv.Close()
+	uploadPhotos(t, srv.Client())
+}
 
+func uploadPhotos(t *testing.T, client *telegraph.TelegraphClient) {
 	path, err := client.UploadFile("data/photo01.jpg")
 	if err != nil {
 		t.Error("Failed to upload photo01 to telegraph:", err)
@@ -45,9 +58,13 @@ func TestUploadPhoto02(t *testing.T) {
 }
 
 func TestUploadPhotoWithWorkerPool(t *testing.T) {
+	srv := telegraphtest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
 	worker := func(indexes chan int, wg *sync.WaitGroup) {
 		for range indexes {
-			TestUploadPhoto01(t)
+			uploadPhotos(t, client)
 			wg.Done()
 		}
 	}
@@ -64,3 +81,182 @@ func TestUploadPhotoWithWorkerPool(t *testing.T) {
 	wg.Wait()
 	close(ch)
 }
+
+func TestUploadReader(t *testing.T) {
+	var calls int
+	var filename, contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		reader, err := r.MultipartReader()
+		if err != nil {
+			t.Error("Server received an invalid multipart request:", err)
+			return
+		}
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Error("Server received no part:", err)
+			return
+		}
+		filename, contentType = part.FileName(), part.Header.Get("Content-Type")
+		_, _ = io.Copy(io.Discard, part)
+		_, _ = w.Write([]byte(`[{"src":"/file/a.gif"}]`))
+	}))
+	defer srv.Close()
+	client := telegraph.GetTelegraphClient(&telegraph.ClientOpt{UploadUrl: srv.URL})
+
+	gif := []byte("GIF89a" + strings.Repeat("\x00", 1024))
+	// A reader without Len or Seek methods, of unknown size.
+	path, err := client.UploadReader(io.MultiReader(bytes.NewReader(gif)), "", nil)
+	if err != nil {
+		t.Fatal("UploadReader failed:", err)
+	}
+	if path != "/file/a.gif" || filename != "file.gif" || contentType != "image/gif" {
+		t.Errorf("UploadReader uploaded %s of type %s and returned %s", filename, contentType, path)
+	}
+
+	_, err = client.UploadReader(strings.NewReader("plain text"), "notes.txt", nil)
+	if !errors.Is(err, telegraph.ErrFileTypeInvalid) {
+		t.Errorf("UploadReader returned %v for a text file, expected ErrFileTypeInvalid", err)
+	}
+	big := append(gif, make([]byte, telegraph.MaxUploadSize)...)
+	_, err = client.UploadReader(bytes.NewReader(big), "big.gif", nil)
+	if !errors.Is(err, telegraph.ErrFileTooBig) {
+		t.Errorf("UploadReader returned %v for a file of known size, expected ErrFileTooBig", err)
+	}
+	if calls != 1 {
+		t.Errorf("UploadReader sent %d requests, expected the invalid files to be rejected before sending", calls)
+	}
+
+	_, err = client.UploadReader(io.MultiReader(bytes.NewReader(big)), "big.gif", nil)
+	var tooBig *telegraph.FileTooBigError
+	if !errors.As(err, &tooBig) || tooBig.Name != "big.gif" || tooBig.Limit != telegraph.MaxUploadSize {
+		t.Errorf("UploadReader returned %v for a file of unknown size, expected FileTooBigError", err)
+	}
+}
+
+func TestUploadStalledReader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+	}))
+	defer srv.Close()
+	client := telegraph.GetTelegraphClient(&telegraph.ClientOpt{UploadUrl: srv.URL})
+
+	// A reader which blocks forever after the head of the file.
+	stalled, pw := io.Pipe()
+	defer pw.Close()
+	r := io.MultiReader(strings.NewReader("GIF89a"+strings.Repeat("\x00", 1024)), stalled)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() {
+		_, err := client.UploadReaderCtx(ctx, r, "", nil)
+		errc <- err
+	}()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("UploadReaderCtx returned %v, expected context.DeadlineExceeded", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("UploadReaderCtx did not return after its context expired")
+	}
+}
+
+func TestUploadFiles(t *testing.T) {
+	srv := telegraphtest.NewServer()
+	defer srv.Close()
+
+	files := []string{"data/photo01.jpg", "data/photo02.jpg"}
+	paths, err := srv.Client().UploadFiles(files...)
+	if err != nil {
+		t.Fatal("UploadFiles failed:", err)
+	}
+	if len(paths) != len(files) {
+		t.Fatalf("UploadFiles returned %d paths for %d files", len(paths), len(files))
+	}
+	for i, file := range files {
+		content, _ := os.ReadFile(file)
+		if uploaded, _ := srv.File(paths[i]); !bytes.Equal(uploaded, content) {
+			t.Errorf("UploadFiles returned path %s which is not %s", paths[i], file)
+		}
+	}
+
+	response := `[{"src":"/file/a.gif"},{"error":"File type invalid"}]`
+	partial := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(response))
+	}))
+	defer partial.Close()
+	client := telegraph.GetTelegraphClient(&telegraph.ClientOpt{UploadUrl: partial.URL})
+
+	gif := []byte("GIF89a")
+	paths, err = client.UploadReaders([]telegraph.UploadSource{
+		{Reader: bytes.NewReader(gif)},
+		{Reader: bytes.NewReader(gif), Name: "second.gif"},
+	}, nil)
+	var filesErr *telegraph.UploadFilesError
+	if !errors.As(err, &filesErr) || filesErr.Errors[0] != nil || !errors.Is(filesErr.Errors[1], telegraph.ErrFileTypeInvalid) {
+		t.Errorf("UploadReaders returned %v, expected the second file to be rejected", err)
+	}
+	if len(paths) != 2 || paths[0] != "/file/a.gif" || paths[1] != "" {
+		t.Errorf("UploadReaders returned paths %q", paths)
+	}
+
+	response = `[]`
+	var decodeErr *telegraph.DecodeError
+	if _, err = client.UploadFileByBytes(gif); !errors.As(err, &decodeErr) {
+		t.Errorf("UploadFileByBytes returned %v for an empty result, expected DecodeError", err)
+	}
+}
+
+func TestUploadProgress(t *testing.T) {
+	srv := telegraphtest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	// Concurrent uploads report their own progress.
+	files := []string{"data/photo01.jpg", "data/photo02.jpg"}
+	reports := make([][]telegraph.UploadProgress, len(files))
+	var wg sync.WaitGroup
+	for i, file := range files {
+		wg.Add(1)
+		go func(i int, file string) {
+			defer wg.Done()
+			_, err := client.UploadFileWithOpts(file, &telegraph.UploadOpts{
+				Progress: func(p telegraph.UploadProgress) { reports[i] = append(reports[i], p) },
+			})
+			if err != nil {
+				t.Error("UploadFileWithOpts failed:", err)
+			}
+		}(i, file)
+	}
+	wg.Wait()
+	for i, file := range files {
+		info, _ := os.Stat(file)
+		if len(reports[i]) == 0 {
+			t.Fatalf("UploadFileWithOpts reported no progress for %s", file)
+		}
+		last := reports[i][len(reports[i])-1]
+		if last.Sent != last.Total || last.Total <= info.Size() {
+			t.Errorf("UploadFileWithOpts last reported %+v for %s of %d bytes", last, file, info.Size())
+		}
+		for j := 1; j < len(reports[i]); j++ {
+			if reports[i][j].Sent <= reports[i][j-1].Sent {
+				t.Errorf("UploadFileWithOpts reported %+v after %+v", reports[i][j], reports[i][j-1])
+			}
+		}
+	}
+
+	// The total is unknown for readers of unknown size.
+	var last telegraph.UploadProgress
+	gif := []byte("GIF89a" + strings.Repeat("\x00", 64*1024))
+	_, err := client.UploadReader(io.MultiReader(bytes.NewReader(gif)), "", &telegraph.UploadOpts{
+		Progress: func(p telegraph.UploadProgress) { last = p },
+	})
+	if err != nil {
+		t.Fatal("UploadReader failed:", err)
+	}
+	if last.Total != -1 || last.Sent <= int64(len(gif)) {
+		t.Errorf("UploadReader last reported %+v for %d bytes of unknown size", last, len(gif))
+	}
+}
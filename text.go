@@ -0,0 +1,138 @@
+package telegraph
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// WordsPerMinute is the reading speed used to estimate the reading time of content.
+const WordsPerMinute = 200
+
+// imageReadingTime is the time added to the reading time for every image.
+const imageReadingTime = 12 * time.Second
+
+// TextStats holds statistics about the content of a page.
+type TextStats struct {
+	// Number of words of the text.
+	Words int
+	// Number of characters of the text, spaces included and line breaks excluded.
+	Characters int
+	// Estimated reading time, based on WordsPerMinute plus a few seconds per image.
+	ReadingTime time.Duration
+	// Number of images (img elements).
+	Images int
+	// Number of links (a elements with an href).
+	Links int
+	// Number of embeds (iframe and video elements).
+	Embeds int
+}
+
+// PlainText returns the text of nodes. Paragraphs and other blocks are separated by an empty line, list items,
+// captions and line breaks start a new line, and the content of pre elements is kept as it is.
+func PlainText(nodes []Node) string {
+	w := &textWriter{}
+	w.write(nodes)
+	return w.b.String()
+}
+
+// PlainText returns the text of the content of the page, see PlainText.
+func (p *Page) PlainText() string {
+	return PlainText(p.Content)
+}
+
+// ContentStats returns statistics about nodes: word and character counts of their plain text, estimated reading
+// time, and the number of images, links and embeds.
+func ContentStats(nodes []Node) TextStats {
+	text := PlainText(nodes)
+	stats := TextStats{
+		Words:      len(strings.Fields(text)),
+		Characters: utf8.RuneCountInString(strings.ReplaceAll(text, "\n", "")),
+	}
+	countElements(nodes, &stats)
+	stats.ReadingTime = time.Duration(stats.Words)*time.Minute/WordsPerMinute +
+		time.Duration(stats.Images)*imageReadingTime
+	return stats
+}
+
+// Stats returns statistics about the content of the page, see ContentStats.
+func (p *Page) Stats() TextStats {
+	return ContentStats(p.Content)
+}
+
+func countElements(nodes []Node, stats *TextStats) {
+	for _, n := range nodes {
+		e, ok := n.(*NodeElement)
+		if !ok {
+			continue
+		}
+		switch e.Tag {
+		case "img":
+			stats.Images++
+		case "a":
+			if e.Attrs["href"] != "" {
+				stats.Links++
+			}
+		case "iframe", "video":
+			stats.Embeds++
+		}
+		countElements(e.Children, stats)
+	}
+}
+
+// textWriter writes text, inserting at most one pending line break or empty line between blocks.
+type textWriter struct {
+	b strings.Builder
+	// breaks is the number of line breaks to write before the next text.
+	breaks int
+}
+
+// lineBreak requests n line breaks before the next text.
+func (w *textWriter) lineBreak(n int) {
+	if n > w.breaks {
+		w.breaks = n
+	}
+}
+
+func (w *textWriter) text(s string) {
+	if s == "" {
+		return
+	}
+	if w.b.Len() > 0 {
+		w.b.WriteString(strings.Repeat("\n", w.breaks))
+	}
+	w.breaks = 0
+	w.b.WriteString(s)
+}
+
+func (w *textWriter) write(nodes []Node) {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case TextNode:
+			w.text(string(n))
+		case string:
+			w.text(n)
+		case *NodeElement:
+			switch n.Tag {
+			case "br":
+				w.breaks++
+			case "li", "figcaption":
+				w.lineBreak(1)
+				w.write(n.Children)
+				w.lineBreak(1)
+			case "pre":
+				w.lineBreak(2)
+				w.text(nodesText(n.Children))
+				w.lineBreak(2)
+			default:
+				if blockTags[n.Tag] {
+					w.lineBreak(2)
+					w.write(n.Children)
+					w.lineBreak(2)
+				} else {
+					w.write(n.Children)
+				}
+			}
+		}
+	}
+}
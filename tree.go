@@ -0,0 +1,357 @@
+package telegraph
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// WalkAction tells Walk how to continue after visiting a node.
+type WalkAction int
+
+const (
+	// WalkContinue continues the traversal with the children of the node, then its siblings.
+	WalkContinue WalkAction = iota
+	// WalkSkipChildren continues the traversal with the siblings of the node, skipping its children.
+	WalkSkipChildren
+	// WalkStop ends the traversal.
+	WalkStop
+)
+
+// WalkFunc is the function called by Walk for every node, along with the element it is a child of (nil for top-level
+// nodes).
+type WalkFunc func(n Node, parent *NodeElement) WalkAction
+
+// Walk traverses nodes depth-first, calling fn for every node before its children.
+// Elements may be modified in place by fn, see Transform to replace or remove nodes.
+func Walk(nodes []Node, fn WalkFunc) {
+	walk(nodes, nil, fn)
+}
+
+// Walk traverses the descendants of the element depth-first, see Walk.
+func (e *NodeElement) Walk(fn WalkFunc) {
+	walk(e.Children, e, fn)
+}
+
+func walk(nodes []Node, parent *NodeElement, fn WalkFunc) bool {
+	for _, n := range nodes {
+		switch fn(n, parent) {
+		case WalkStop:
+			return false
+		case WalkSkipChildren:
+			continue
+		}
+		if e, ok := n.(*NodeElement); ok {
+			if !walk(e.Children, e, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Transform replaces every node of the tree by the nodes returned by fn, and returns the new top-level nodes.
+// The tree is traversed depth-first and the children of an element are transformed before the element itself.
+// fn returns []Node{n} to keep n, nil to remove it, or any other nodes to replace it.
+func Transform(nodes []Node, fn func(n Node) []Node) []Node {
+	var out []Node
+	for _, n := range nodes {
+		if e, ok := n.(*NodeElement); ok {
+			e.Children = Transform(e.Children, fn)
+		}
+		out = append(out, fn(n)...)
+	}
+	return out
+}
+
+// Selector is a parsed selector query, see ParseSelector.
+type Selector struct {
+	// groups are the comma separated alternatives of the selector.
+	groups [][]selectorStep
+}
+
+// selectorStep is a compound selector along with the combinator relating it to the previous step.
+type selectorStep struct {
+	// child is true when the step must be a child of the previous one (">"), a descendant otherwise.
+	child bool
+	tag   string
+	attrs []selectorAttr
+}
+
+type selectorAttr struct {
+	key, op, value string
+}
+
+// ParseSelector parses a CSS-like selector query. The supported syntax is a subset of CSS:
+//   - tag names and the universal selector: img, *
+//   - attribute selectors: [href], [href="..."], [href^="..."], [href$="..."], [href*="..."]
+//   - descendant and child combinators: blockquote a, ul > li
+//   - selector lists: h3, h4
+func ParseSelector(selector string) (*Selector, error) {
+	groups, err := scanSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %w", selector, err)
+	}
+	s := &Selector{}
+	for _, tokens := range groups {
+		steps, err := parseSelectorGroup(tokens)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", selector, err)
+		}
+		s.groups = append(s.groups, steps)
+	}
+	return s, nil
+}
+
+// scanSelector splits selector into its comma separated groups of tokens, a token being either a compound selector or
+// the ">" combinator. Commas, whitespace and ">" inside attribute selectors are part of the compound selector.
+func scanSelector(selector string) ([][]string, error) {
+	var (
+		groups [][]string
+		tokens []string
+		token  strings.Builder
+		inAttr bool
+		quote  rune
+	)
+	flush := func() {
+		if token.Len() > 0 {
+			tokens = append(tokens, token.String())
+			token.Reset()
+		}
+	}
+	for _, r := range selector {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case inAttr:
+			if r == '"' || r == '\'' {
+				quote = r
+			} else if r == ']' {
+				inAttr = false
+			}
+		case r == '[':
+			inAttr = true
+		case r == ',':
+			flush()
+			groups, tokens = append(groups, tokens), nil
+			continue
+		case r == '>':
+			flush()
+			tokens = append(tokens, ">")
+			continue
+		case unicode.IsSpace(r):
+			flush()
+			continue
+		}
+		token.WriteRune(r)
+	}
+	if inAttr {
+		return nil, fmt.Errorf("unterminated attribute selector")
+	}
+	flush()
+	return append(groups, tokens), nil
+}
+
+func parseSelectorGroup(tokens []string) ([]selectorStep, error) {
+	var (
+		steps []selectorStep
+		child bool
+	)
+	for _, token := range tokens {
+		if token == ">" {
+			if len(steps) == 0 || child {
+				return nil, fmt.Errorf("unexpected >")
+			}
+			child = true
+			continue
+		}
+		step, err := parseSelectorStep(token)
+		if err != nil {
+			return nil, err
+		}
+		step.child = child
+		child = false
+		steps = append(steps, step)
+	}
+	if len(steps) == 0 || child {
+		return nil, fmt.Errorf("empty selector")
+	}
+	return steps, nil
+}
+
+func parseSelectorStep(token string) (selectorStep, error) {
+	var step selectorStep
+	i := strings.IndexByte(token, '[')
+	if i < 0 {
+		i = len(token)
+	}
+	step.tag = strings.ToLower(token[:i])
+	if step.tag == "*" {
+		step.tag = ""
+	}
+	for rest := token[i:]; rest != ""; {
+		end := attrEnd(rest)
+		if rest[0] != '[' || end < 0 {
+			return step, fmt.Errorf("malformed attribute selector %q", rest)
+		}
+		attr := selectorAttr{key: rest[1:end]}
+		if j := strings.IndexByte(attr.key, '='); j >= 0 {
+			attr.key, attr.op, attr.value = strings.TrimSpace(attr.key[:j]), "=", unquote(strings.TrimSpace(attr.key[j+1:]))
+			if k := len(attr.key) - 1; k >= 0 && strings.ContainsRune("^$*", rune(attr.key[k])) {
+				attr.key, attr.op = attr.key[:k], attr.key[k:]+"="
+			}
+		}
+		attr.key = strings.ToLower(strings.TrimSpace(attr.key))
+		step.attrs = append(step.attrs, attr)
+		rest = rest[end+1:]
+	}
+	return step, nil
+}
+
+// attrEnd returns the index of the "]" closing the attribute selector at the start of s, skipping quoted values, or -1.
+func attrEnd(s string) int {
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// unquote removes the quotes around an attribute value, if any.
+func unquote(v string) string {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+func (s selectorStep) match(e *NodeElement) bool {
+	if s.tag != "" && s.tag != e.Tag {
+		return false
+	}
+	for _, a := range s.attrs {
+		v, ok := e.Attrs[a.key]
+		if !ok {
+			return false
+		}
+		switch a.op {
+		case "=":
+			ok = v == a.value
+		case "^=":
+			ok = strings.HasPrefix(v, a.value)
+		case "$=":
+			ok = strings.HasSuffix(v, a.value)
+		case "*=":
+			ok = strings.Contains(v, a.value)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether e, whose ancestors are given from the root, matches the selector.
+func (s *Selector) matches(e *NodeElement, ancestors []*NodeElement) bool {
+	for _, steps := range s.groups {
+		if matchSteps(steps, e, ancestors) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSteps(steps []selectorStep, e *NodeElement, ancestors []*NodeElement) bool {
+	last := steps[len(steps)-1]
+	if !last.match(e) {
+		return false
+	}
+	if len(steps) == 1 {
+		return true
+	}
+	if last.child {
+		return len(ancestors) > 0 && matchSteps(steps[:len(steps)-1], ancestors[len(ancestors)-1], ancestors[:len(ancestors)-1])
+	}
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		if matchSteps(steps[:len(steps)-1], ancestors[i], ancestors[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Query returns the elements of nodes, in depth-first order, matching the selector.
+func (s *Selector) Query(nodes []Node) []*NodeElement {
+	var found []*NodeElement
+	s.query(nodes, nil, func(e *NodeElement) bool {
+		found = append(found, e)
+		return true
+	})
+	return found
+}
+
+// First returns the first element of nodes, in depth-first order, matching the selector, or nil.
+func (s *Selector) First(nodes []Node) *NodeElement {
+	var found *NodeElement
+	s.query(nodes, nil, func(e *NodeElement) bool {
+		found = e
+		return false
+	})
+	return found
+}
+
+func (s *Selector) query(nodes []Node, ancestors []*NodeElement, found func(*NodeElement) bool) bool {
+	for _, n := range nodes {
+		e, ok := n.(*NodeElement)
+		if !ok {
+			continue
+		}
+		if s.matches(e, ancestors) && !found(e) {
+			return false
+		}
+		if !s.query(e.Children, append(ancestors, e), found) {
+			return false
+		}
+	}
+	return true
+}
+
+// Query returns the elements of nodes, in depth-first order, matching the selector, see ParseSelector.
+// e.g. Query(page.Content, "blockquote a[href]") returns all links inside blockquotes.
+func Query(nodes []Node, selector string) ([]*NodeElement, error) {
+	s, err := ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	return s.Query(nodes), nil
+}
+
+// QueryFirst returns the first element of nodes, in depth-first order, matching the selector, or nil.
+func QueryFirst(nodes []Node, selector string) (*NodeElement, error) {
+	s, err := ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	return s.First(nodes), nil
+}
+
+// Query returns the descendants of the element matching the selector, see Query.
+func (e *NodeElement) Query(selector string) ([]*NodeElement, error) {
+	return Query(e.Children, selector)
+}
+
+// QueryFirst returns the first descendant of the element matching the selector, or nil.
+func (e *NodeElement) QueryFirst(selector string) (*NodeElement, error) {
+	return QueryFirst(e.Children, selector)
+}
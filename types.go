@@ -6,18 +6,33 @@ import (
 
 // TelegraphClient is the client that contains all library methods implemented on it.
 type TelegraphClient struct {
-	// Api URL of the Telegraph API.
+	// Api URL of the Telegraph API, DefaultApiUrl if empty.
 	ApiUrl string
+	// URL of the endpoint all files are uploaded to, DefaultUploadUrl if empty.
+	UploadUrl string
 	// HttpClient is the http client used to send http requests to the Telegraph API.
 	HttpClient *http.Client
+	// RetryPolicy controls how failed requests are retried. Nil disables retries.
+	RetryPolicy *RetryPolicy
+	// RateLimiter throttles all requests sent by the client. Nil disables client-side rate limiting.
+	RateLimiter *RateLimiter
 }
 
 // ClientOpt is the options used to construct the TelegraphClient value.
+// ApiUrl and UploadUrl are the only hosts the client sends requests to, set both to use a mirror, a proxy or a test
+// server such as the one of the telegraphtest package.
 type ClientOpt struct {
-	// Api URL of the Telegraph API.
+	// Api URL of the Telegraph API, DefaultApiUrl by default.
 	ApiUrl string
+	// URL of the endpoint all files are uploaded to, DefaultUploadUrl by default.
+	UploadUrl string
 	// HttpClient is the http client used to send http requests to the Telegraph API.
 	HttpClient *http.Client
+	// RetryPolicy controls how failed requests are retried, e.g. on FLOOD_WAIT_X errors. Nil disables retries.
+	RetryPolicy *RetryPolicy
+	// RateLimiter throttles all requests sent by the client, it may be shared by several clients. Nil disables
+	// client-side rate limiting.
+	RateLimiter *RateLimiter
 }
 
 // Account represents a Telegraph account.
@@ -122,8 +137,8 @@ type PageViewsOpts struct {
 	Hour int64 `json:"hour,omitempty"`
 }
 
-// Node is abstract object represents a DOM Node. It can be a String which represents a DOM text node or a
-// NodeElement object.
+// Node is abstract object represents a DOM Node. It can be a TextNode which represents a DOM text node or a
+// *NodeElement object. Plain string values are also accepted as text nodes when sending content.
 type Node interface{}
 
 // NodeElement represents a DOM element node.
@@ -144,4 +159,6 @@ type NodeElement struct {
 type Upload struct {
 	// Path to the image.
 	Path string `json:"src"`
+	// Error describing why the file was not uploaded, when other files of the same request were.
+	Error string `json:"error,omitempty"`
 }
@@ -0,0 +1,465 @@
+package telegraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// MaxUploadSize is the maximum size in bytes of a file accepted by the Telegraph upload endpoint.
+const MaxUploadSize = 5 * 1024 * 1024
+
+// uploadTypes are the content types accepted by the Telegraph upload endpoint, with the extension of their files.
+var uploadTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"video/mp4":  ".mp4",
+}
+
+// UploadOpts is the optional parameters for UploadReader, UploadReaders and the WithOpts variants of UploadFile,
+// UploadFileByBytes and UploadFiles.
+type UploadOpts struct {
+	// ContentType of the file, e.g. image/png. If empty, it is detected from the first 512 bytes of the file with
+	// http.DetectContentType.
+	ContentType string
+	// MaxSize is the maximum size in bytes of the file. (default = MaxUploadSize)
+	MaxSize int64
+	// Progress is called from the goroutine sending the request every time a chunk of the request body is handed to
+	// the connection, including the multipart headers. Reports are counted per request, so concurrent uploads may
+	// share a callback, and start over if the request is retried. To receive the reports on a channel without
+	// blocking the upload, use a callback like:
+	//
+	//	func(p telegraph.UploadProgress) {
+	//		select {
+	//		case ch <- p:
+	//		default:
+	//		}
+	//	}
+	Progress func(UploadProgress)
+}
+
+// UploadProgress is the progress of an upload request.
+type UploadProgress struct {
+	// Sent is the number of bytes of the request body sent so far.
+	Sent int64
+	// Total is the size in bytes of the request body, -1 if the size of a file is unknown.
+	Total int64
+}
+
+// progress returns the progress callback of o, which may be nil.
+func (o *UploadOpts) progress() func(UploadProgress) {
+	if o == nil {
+		return nil
+	}
+	return o.Progress
+}
+
+// progressReader reports the bytes read from the body of a request.
+type progressReader struct {
+	r        io.Reader
+	sent     int64
+	total    int64
+	progress func(UploadProgress)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.progress(UploadProgress{Sent: p.sent, Total: p.total})
+	}
+	return n, err
+}
+
+// FileTooBigError is returned by uploads when a file is bigger than the size limit. If the size of the file is
+// known (files, byte slices and seekable readers) it is returned before anything is sent, otherwise the upload is
+// aborted as soon as the limit is crossed. It matches ErrFileTooBig with errors.Is.
+type FileTooBigError struct {
+	// Name of the file.
+	Name string
+	// Size is the size of the file in bytes, or the number of bytes read when the limit was crossed.
+	Size int64
+	// Limit is the size limit in bytes.
+	Limit int64
+}
+
+func (e *FileTooBigError) Error() string {
+	return fmt.Sprintf("file %q is too big: %d bytes over the limit of %d bytes", e.Name, e.Size-e.Limit, e.Limit)
+}
+
+// Is makes FileTooBigError match ErrFileTooBig.
+func (e *FileTooBigError) Is(target error) bool {
+	return target == ErrFileTooBig
+}
+
+// FileTypeError is returned by uploads, before anything is sent, when the content type of a file is not accepted by
+// Telegraph. It matches ErrFileTypeInvalid with errors.Is.
+type FileTypeError struct {
+	// Name of the file.
+	Name string
+	// ContentType of the file.
+	ContentType string
+}
+
+func (e *FileTypeError) Error() string {
+	return fmt.Sprintf("file %q has unsupported type %s", e.Name, e.ContentType)
+}
+
+// Is makes FileTypeError match ErrFileTypeInvalid.
+func (e *FileTypeError) Is(target error) bool {
+	return target == ErrFileTypeInvalid
+}
+
+// UploadSource is a file to upload with UploadReaders.
+type UploadSource struct {
+	// Reader the content of the file is read from.
+	Reader io.Reader
+	// Name of the file, derived from its content type if empty.
+	Name string
+	// ContentType of the file, e.g. image/png. If empty, UploadOpts.ContentType is used, or the content type is
+	// detected from the first 512 bytes of the file.
+	ContentType string
+}
+
+// UploadFilesError is returned by UploadFiles and UploadReaders when Telegraph rejected some of the files of a
+// request. The paths of the other files are returned along with it.
+type UploadFilesError struct {
+	// Errors holds the error of each rejected file at the index of the file, and nil for the uploaded files.
+	Errors []error
+}
+
+func (e *UploadFilesError) Error() string {
+	var (
+		failed int
+		first  error
+	)
+	for _, err := range e.Errors {
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+			failed++
+		}
+	}
+	return fmt.Sprintf("%d of %d files were not uploaded: %v", failed, len(e.Errors), first)
+}
+
+// uploadFile is a file ready to be uploaded.
+type uploadFile struct {
+	name, contentType string
+	r                 io.Reader
+	// seeker is set if r can be rewound to offset to send the file again.
+	seeker io.Seeker
+	offset int64
+	// size of the file, -1 if unknown.
+	size, limit int64
+	// read is the number of bytes read from r and err the first error it returned, size errors included.
+	read int64
+	// mu guards err, which is checked while an abandoned request may still be reading the file.
+	mu  sync.Mutex
+	err error
+}
+
+// newUploadFile detects the content type and size of the file read from r and checks them against the limits of
+// Telegraph.
+func newUploadFile(r io.Reader, name string, opts *UploadOpts) (*uploadFile, error) {
+	if opts == nil {
+		opts = &UploadOpts{}
+	}
+	f := &uploadFile{name: name, r: r, size: -1, limit: opts.MaxSize}
+	if f.limit <= 0 {
+		f.limit = MaxUploadSize
+	}
+	if s, ok := r.(io.Seeker); ok {
+		if offset, err := s.Seek(0, io.SeekCurrent); err == nil {
+			f.seeker, f.offset = s, offset
+		}
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	head = head[:n]
+
+	f.contentType = opts.ContentType
+	if f.contentType == "" {
+		f.contentType = http.DetectContentType(head)
+	}
+	mediaType, _, _ := mime.ParseMediaType(f.contentType)
+	ext, ok := uploadTypes[mediaType]
+	if f.name == "" {
+		f.name = "file" + ext
+	}
+	if !ok {
+		return nil, &FileTypeError{Name: f.name, ContentType: f.contentType}
+	}
+
+	switch {
+	case f.seeker != nil:
+		if f.size, err = f.seeker.Seek(0, io.SeekEnd); err != nil {
+			return nil, err
+		}
+		f.size -= f.offset
+		if _, err = f.seeker.Seek(f.offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+	default:
+		if l, ok := r.(interface{ Len() int }); ok {
+			f.size = int64(n + l.Len())
+		}
+		f.r = io.MultiReader(bytes.NewReader(head), r)
+	}
+	if f.size > f.limit {
+		return nil, &FileTooBigError{Name: f.name, Size: f.size, Limit: f.limit}
+	}
+	return f, nil
+}
+
+func (f *uploadFile) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	f.read += int64(n)
+	if f.read > f.limit {
+		err = &FileTooBigError{Name: f.name, Size: f.read, Limit: f.limit}
+	}
+	if err != nil && err != io.EOF {
+		f.mu.Lock()
+		if f.err == nil {
+			f.err = err
+		}
+		f.mu.Unlock()
+	}
+	return n, err
+}
+
+// readErr returns the first error returned by r.
+func (f *uploadFile) readErr() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.err
+}
+
+// rewind prepares the file to be sent again, it reports whether it is possible.
+func (f *uploadFile) rewind() bool {
+	if f.read == 0 {
+		return true
+	}
+	if f.seeker == nil {
+		return false
+	}
+	if _, err := f.seeker.Seek(f.offset, io.SeekStart); err != nil {
+		return false
+	}
+	f.read = 0
+	f.mu.Lock()
+	f.err = nil
+	f.mu.Unlock()
+	return true
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func (f *uploadFile) header() textproto.MIMEHeader {
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, quoteEscaper.Replace(f.name)))
+	h.Set("Content-Type", f.contentType)
+	return h
+}
+
+// writeParts writes the files as the parts of a multipart request.
+func writeParts(w *multipart.Writer, files []*uploadFile) error {
+	for _, f := range files {
+		part, err := w.CreatePart(f.header())
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(part, f); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// countWriter counts the bytes written to it.
+type countWriter int64
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	*c += countWriter(len(p))
+	return len(p), nil
+}
+
+// bodySize returns the size of the multipart request body written by writeParts with the given boundary, or -1 if
+// the size of a file is unknown.
+func bodySize(boundary string, files []*uploadFile) int64 {
+	var size countWriter
+	w := multipart.NewWriter(&size)
+	if err := w.SetBoundary(boundary); err != nil {
+		return -1
+	}
+	var content int64
+	for _, f := range files {
+		if f.size < 0 {
+			return -1
+		}
+		if _, err := w.CreatePart(f.header()); err != nil {
+			return -1
+		}
+		content += f.size
+	}
+	if err := w.Close(); err != nil {
+		return -1
+	}
+	return int64(size) + content
+}
+
+// upload sends the files to the upload endpoint in a single multipart request, streamed from their readers, and
+// returns their paths in order. If some files were rejected, the paths are returned along with an UploadFilesError.
+// The request is retried according to the RetryPolicy of the client only if all the files can be rewound.
+// If progress is not nil, it is called with the progress of every attempt.
+func (c *TelegraphClient) upload(ctx context.Context, files []*uploadFile, progress func(UploadProgress)) (paths []string, err error) {
+	var writing <-chan struct{}
+	attempt := func() error {
+		if err := c.wait(ctx, "upload", ""); err != nil {
+			return err
+		}
+		paths, writing, err = c.uploadOnce(ctx, files, progress)
+		c.observe("upload", "", err)
+		return err
+	}
+	for _, f := range files {
+		if f.seeker == nil {
+			err = attempt()
+			return paths, err
+		}
+	}
+	err = c.withRetry(ctx, func() error {
+		if writing != nil {
+			// The files are only rewound once the failed attempt stopped reading them.
+			select {
+			case <-writing:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		for _, f := range files {
+			if !f.rewind() {
+				return fmt.Errorf("failed to rewind %q", f.name)
+			}
+		}
+		return attempt()
+	})
+	return paths, err
+}
+
+// uploadOnce sends a single upload request. The files are written to the request body by a goroutine, which is not
+// waited for: a file whose reader blocks must not hold back the error of a failed or cancelled request. The returned
+// channel is closed once the goroutine stopped reading the files.
+func (c *TelegraphClient) uploadOnce(ctx context.Context, files []*uploadFile, progress func(UploadProgress)) ([]string, <-chan struct{}, error) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = pw.CloseWithError(writeParts(writer, files))
+	}()
+	defer func() {
+		_ = pr.Close()
+	}()
+	// The transport only returns once it stopped reading the body, so the body is closed as soon as ctx is done.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = pr.CloseWithError(ctx.Err())
+		case <-stop:
+		}
+	}()
+	paths, err := c.sendUpload(ctx, pr, writer, files, progress)
+	return paths, done, err
+}
+
+// sendUpload sends the multipart body read from pr and decodes the response.
+func (c *TelegraphClient) sendUpload(ctx context.Context, pr *io.PipeReader, writer *multipart.Writer, files []*uploadFile, progress func(UploadProgress)) ([]string, error) {
+
+	total := bodySize(writer.Boundary(), files)
+	var body io.Reader = pr
+	if progress != nil {
+		body = &progressReader{r: pr, total: total, progress: progress}
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.uploadUrl(), body)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	if total >= 0 {
+		request.ContentLength = total
+	}
+
+	httpResponse, err := c.HttpClient.Do(request)
+	if err != nil {
+		_ = pr.CloseWithError(err)
+		// Report why the files could not be read rather than the resulting transport error. A read error is recorded
+		// before it closes the pipe, and thus before it fails the request.
+		for _, f := range files {
+			if err := f.readErr(); err != nil {
+				return nil, err
+			}
+		}
+		return nil, &TransportError{Method: "upload", Err: err}
+	}
+	defer func() {
+		_ = httpResponse.Body.Close()
+	}()
+
+	b, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, &TransportError{Method: "upload", Err: err}
+	}
+
+	var results []Upload
+	if err := json.Unmarshal(b, &results); err != nil {
+		m := map[string]string{}
+		if json.Unmarshal(b, &m) != nil || m["error"] == "" {
+			if httpResponse.StatusCode < 200 || httpResponse.StatusCode > 299 {
+				return nil, &HTTPError{Method: "upload", StatusCode: httpResponse.StatusCode, Status: httpResponse.Status}
+			}
+			return nil, &DecodeError{Method: "upload", Err: err}
+		}
+		return nil, newAPIError("upload", m["error"])
+	}
+	if len(results) != len(files) {
+		return nil, &DecodeError{Method: "upload", Err: fmt.Errorf("got %d upload results for %d files", len(results), len(files))}
+	}
+
+	paths := make([]string, len(results))
+	var failed *UploadFilesError
+	for i, u := range results {
+		paths[i] = u.Path
+		if u.Path != "" {
+			continue
+		}
+		if failed == nil {
+			failed = &UploadFilesError{Errors: make([]error, len(results))}
+		}
+		if u.Error != "" {
+			failed.Errors[i] = newAPIError("upload", u.Error)
+		} else {
+			failed.Errors[i] = &DecodeError{Method: "upload", Err: fmt.Errorf("no path returned for %q", files[i].name)}
+		}
+	}
+	if failed != nil {
+		return paths, failed
+	}
+	return paths, nil
+}
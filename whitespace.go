@@ -0,0 +1,103 @@
+package telegraph
+
+import "strings"
+
+// blockTags is the set of supported tags that are rendered as blocks, around which whitespace is not significant.
+var blockTags = map[string]bool{
+	"aside": true, "blockquote": true, "figcaption": true, "figure": true, "h3": true, "h4": true, "hr": true,
+	"iframe": true, "li": true, "ol": true, "p": true, "pre": true, "ul": true, "video": true,
+}
+
+// collapseWhitespace collapses whitespace in nodes the way browsers render it: runs of whitespace become a single
+// space, and whitespace at the start and end of blocks and around line breaks is removed. The content of <pre>
+// elements is left untouched.
+func collapseWhitespace(nodes []Node) []Node {
+	c := &collapser{atLineStart: true}
+	return c.trimTrailing(c.collapse(nodes))
+}
+
+type collapser struct {
+	// atLineStart is true when the next text starts a line, or follows a space, so that its leading space is dropped.
+	atLineStart bool
+}
+
+func (c *collapser) collapse(nodes []Node) []Node {
+	out := nodes[:0]
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case TextNode:
+			if t := c.text(string(n)); t != "" {
+				out = append(out, TextNode(t))
+			}
+		case string:
+			if t := c.text(n); t != "" {
+				out = append(out, TextNode(t))
+			}
+		case *NodeElement:
+			switch {
+			case n.Tag == "pre":
+				out = c.trimTrailing(out)
+				c.atLineStart = true
+			case blockTags[n.Tag]:
+				out = c.trimTrailing(out)
+				c.atLineStart = true
+				n.Children = c.trimTrailing(c.collapse(n.Children))
+				c.atLineStart = true
+			case n.Tag == "br":
+				out = c.trimTrailing(out)
+				c.atLineStart = true
+			case n.Tag == "img":
+				c.atLineStart = false
+			default:
+				n.Children = c.collapse(n.Children)
+			}
+			out = append(out, n)
+		default:
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// text collapses the whitespace of s, dropping its leading space if the previous text already ended with one.
+func (c *collapser) text(s string) string {
+	var b strings.Builder
+	space := c.atLineStart
+	for _, r := range s {
+		if isCollapsibleSpace(r) {
+			if !space {
+				b.WriteByte(' ')
+				space = true
+			}
+			continue
+		}
+		b.WriteRune(r)
+		space = false
+	}
+	c.atLineStart = space
+	return b.String()
+}
+
+// trimTrailing removes the trailing space of the last text in nodes, looking into inline elements.
+func (c *collapser) trimTrailing(nodes []Node) []Node {
+	if len(nodes) == 0 {
+		return nodes
+	}
+	switch n := nodes[len(nodes)-1].(type) {
+	case TextNode:
+		if t := strings.TrimRight(string(n), " "); t != "" {
+			nodes[len(nodes)-1] = TextNode(t)
+		} else {
+			nodes = nodes[:len(nodes)-1]
+		}
+	case *NodeElement:
+		if !blockTags[n.Tag] && n.Tag != "br" {
+			n.Children = c.trimTrailing(n.Children)
+		}
+	}
+	return nodes
+}
+
+func isCollapsibleSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f'
+}